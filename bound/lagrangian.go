@@ -0,0 +1,115 @@
+// Package bound computes lower bounds on the objective of the selective cycle problem,
+// to tell how far solutions are from the optimum.
+package bound
+
+import (
+	"evolutionary_computation/utils"
+	"math"
+	"sort"
+)
+
+// Lagrangian parameters: the subgradient step is Theta*(upper-L)/|g|^2, Theta is halved
+// after Patience iterations without improvement and the search stops below MinTheta.
+const (
+	InitialTheta = 2.0
+	MinTheta     = 1e-4
+	Patience     = 20
+	MaxSteps     = 3000
+)
+
+// Bound is a lower bound with how it was obtained.
+type Bound struct {
+	Value int `json:"value"`
+	Steps int `json:"steps"` // subgradient steps taken
+}
+
+// Lagrangian returns a lower bound on the fitness of every feasible solution of the problem.
+//
+// In a cycle every selected node v is joined to two selected neighbours, so the objective is the
+// sum over the selected nodes of c(v) + (d(v,prev) + d(v,next))/2. Each selected node is also the
+// neighbour of exactly two selected nodes, which lets us add the multipliers mu:
+//
+//	sum over v in S of  c(v) - mu(v) + (d(v,prev) + mu(prev) + d(v,next) + mu(next))/2
+//
+// Letting every node pick its two cheapest neighbours by d(v,u)+mu(u), selected or not, and
+// selecting the cheapest allowed number of nodes gives a bound for any mu. The multipliers are
+// raised by subgradient ascent on nodes picked as neighbours more often than they are selected.
+// The upper bound, such as the fitness of a good solution, only scales the steps.
+func Lagrangian(problem *utils.Problem, upper int) Bound {
+	n := problem.NumNodes()
+	mu := make([]float64, n)
+	value := make([]float64, n)
+	first, second := make([]int, n), make([]int, n)
+	order := make([]int, n)
+	g := make([]float64, n)
+
+	best := math.Inf(-1)
+	theta := InitialTheta
+	sinceImproved := 0
+	steps := 0
+	for ; steps < MaxSteps && theta > MinTheta; steps++ {
+		for v := 0; v < n; v++ {
+			d1, d2 := math.Inf(1), math.Inf(1)
+			for u := 0; u < n; u++ {
+				if u == v {
+					continue
+				}
+				d := float64(problem.Distance(v, u)) + mu[u]
+				if d < d1 {
+					d2, second[v] = d1, first[v]
+					d1, first[v] = d, u
+				} else if d < d2 {
+					d2, second[v] = d, u
+				}
+			}
+			value[v] = float64(problem.Cost(v)) - mu[v] + (d1+d2)/2
+			order[v] = v
+		}
+		sort.Slice(order, func(i, j int) bool { return value[order[i]] < value[order[j]] })
+
+		// The cheapest allowed number of the cheapest nodes
+		bound, sum, size := math.Inf(1), 0.0, 0
+		for k, v := range order[:problem.Max] {
+			sum += value[v]
+			if k+1 >= problem.Min && sum < bound {
+				bound, size = sum, k+1
+			}
+		}
+
+		if bound > best+1e-9 {
+			best, sinceImproved = bound, 0
+		} else if sinceImproved++; sinceImproved >= Patience {
+			theta, sinceImproved = theta/2, 0
+		}
+
+		for u := range g {
+			g[u] = 0
+		}
+		for _, v := range order[:size] {
+			g[v]--
+			g[first[v]] += 0.5
+			g[second[v]] += 0.5
+		}
+		norm := 0.0
+		for _, x := range g {
+			norm += x * x
+		}
+		if norm == 0 || float64(upper) <= bound {
+			// The relaxed solution is a set of cycles, or the bound can't get better
+			steps++
+			break
+		}
+		step := theta * (float64(upper) - bound) / norm
+		for u := range mu {
+			mu[u] += step * g[u]
+		}
+	}
+
+	// Fitness values are integers
+	return Bound{Value: int(math.Ceil(best - 1e-6)), Steps: steps}
+}
+
+// Gap returns how far the fitness is above the lower bound, relative to the bound.
+func Gap(fitness, lower int) float64 {
+	return float64(fitness-lower) / math.Max(1, math.Abs(float64(lower)))
+}
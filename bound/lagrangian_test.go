@@ -0,0 +1,83 @@
+package bound_test
+
+import (
+	"context"
+	"evolutionary_computation/bound"
+	"evolutionary_computation/methods"
+	"evolutionary_computation/utils"
+	"math/rand"
+	"testing"
+)
+
+// newRandomProblem returns a problem on random points with costs from minCost up to 500.
+func newRandomProblem(t *testing.T, seed int64, numNodes, minCost int, selection utils.Selection, weights utils.Weights) *utils.Problem {
+	t.Helper()
+	rng := rand.New(rand.NewSource(seed))
+	nodes := make([]utils.Node, numNodes)
+	for i := range nodes {
+		nodes[i] = utils.Node{ID: i, X: float64(rng.Intn(1000)), Y: float64(rng.Intn(1000)), Cost: minCost + rng.Intn(500-minCost)}
+	}
+	instance := &utils.Instance{Nodes: nodes, Distances: utils.EuclideanDistances(nodes), HasCoordinates: true}
+	problem, err := utils.NewProblem(instance, selection, weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return problem
+}
+
+func TestLagrangianBelowOptimum(t *testing.T) {
+	tests := []struct {
+		name      string
+		numNodes  int
+		minCost   int
+		selection utils.Selection
+		weights   utils.Weights
+	}{
+		{"half", 14, 0, utils.HalfSelection(14), utils.UnitWeights},
+		{"all nodes", 10, 0, utils.Selection{Min: 10, Max: 10}, utils.UnitWeights},
+		{"smallest cycle", 10, 0, utils.Selection{Min: 3, Max: 3}, utils.UnitWeights},
+		{"free with prizes", 12, -400, utils.Selection{Min: 3, Max: 12}, utils.UnitWeights},
+		{"free within a range", 12, -300, utils.Selection{Min: 5, Max: 9}, utils.UnitWeights},
+		{"weighted", 12, 0, utils.HalfSelection(12), utils.Weights{Alpha: 3, Beta: 1}},
+		{"costs only", 12, -200, utils.Selection{Min: 3, Max: 12}, utils.Weights{Alpha: 0, Beta: 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for seed := int64(0); seed < 5; seed++ {
+				problem := newRandomProblem(t, seed, test.numNodes, test.minCost, test.selection, test.weights)
+				solution, err := methods.Exact(context.Background(), problem)
+				if err != nil {
+					t.Fatal(err)
+				}
+				optimum := problem.Fitness(solution)
+				// The upper bound only scales the steps, a poor one must not break the bound
+				for _, upper := range []int{optimum, optimum + 1000, 2 * max(optimum, 1)} {
+					lower := bound.Lagrangian(problem, upper)
+					if lower.Value > optimum {
+						t.Fatalf("seed %d, upper bound %d: lower bound %d above the optimum %d", seed, upper, lower.Value, optimum)
+					}
+					if gap := bound.Gap(optimum, lower.Value); gap < 0 {
+						t.Fatalf("seed %d: negative gap %g of the optimum", seed, gap)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGap(t *testing.T) {
+	tests := []struct {
+		fitness, lower int
+		gap            float64
+	}{
+		{110, 100, 0.1},
+		{100, 100, 0},
+		{-90, -100, 0.1},
+		{5, 0, 5}, // relative to 1 when the bound is 0
+	}
+	for _, test := range tests {
+		if gap := bound.Gap(test.fitness, test.lower); gap != test.gap {
+			t.Fatalf("Gap(%d, %d) = %g, expected %g", test.fitness, test.lower, gap, test.gap)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"evolutionary_computation/experiment"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// batchCommand runs every configuration of an experiment manifest, skipping the ones whose
+// results are already complete.
+func batchCommand(args []string) {
+	flags := flag.NewFlagSet("batch", flag.ExitOnError)
+	rerun := flags.Bool("rerun", false, "run all configurations, even those with complete results")
+	dryRun := flags.Bool("dry-run", false, "only list the configurations and whether they would run")
+	hook := flags.String("hook", "", "optional post-processing command run on each results.json")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go run . batch [flags] <manifest.json>\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+	if len(args) != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	manifest, err := experiment.LoadManifest(args[0])
+	if err != nil {
+		log.Fatalf("Error loading manifest: %v", err)
+	}
+	batchSeed := manifest.Seed
+	if batchSeed == 0 {
+		batchSeed = solver.NewSeed()
+	}
+	ctx := interruptible()
+	batchWeights := utils.Weights{Alpha: *manifest.Alpha, Beta: *manifest.Beta}
+	sink := experiment.Sink{Root: manifest.Logs, Hook: *hook}
+
+	// Check the whole manifest before running anything
+	type job struct {
+		experiment.Configuration
+		method  solver.Solver
+		params  solver.Params
+		problem *utils.Problem
+	}
+	instances := make(map[string]*utils.Instance)
+	problems := make(map[string]*utils.Problem)
+	var jobs []job
+	configs, err := manifest.Configurations()
+	if err != nil {
+		log.Fatalf("Invalid manifest: %v", err)
+	}
+	for _, config := range configs {
+		if _, ok := problems[config.Instance]; !ok {
+			instance, err := utils.LoadInstance(config.Instance)
+			if err != nil {
+				log.Fatalf("Error loading instance from %s: %v", config.Instance, err)
+			}
+			selection, err := utils.ParseSelection(manifest.Select, len(instance.Nodes))
+			if err != nil {
+				log.Fatalf("Invalid selection for %s: %v", config.Instance, err)
+			}
+			problem, err := utils.NewProblem(instance, selection, batchWeights)
+			if err != nil {
+				log.Fatalf("Invalid problem %s: %v", config.Instance, err)
+			}
+			instances[config.Instance], problems[config.Instance] = instance, problem
+		}
+		method, ok := solver.Lookup(config.Method)
+		if !ok {
+			log.Fatalf("Unknown method: %s", config.Method)
+		}
+		params, err := solver.Resolve(method.Params(), config.Overrides)
+		if err != nil {
+			log.Fatalf("Invalid parameters for %s: %v", config.Method, err)
+		}
+		jobs = append(jobs, job{config, method, params, problems[config.Instance]})
+	}
+
+	// Calibrated budgets are measured once per instance, when a configuration needs them
+	calibrated := make(map[string]solver.Budget)
+	skipped := 0
+	for k, j := range jobs {
+		dir := sink.Dir(j.Method, j.Instance, j.Variant)
+		label := fmt.Sprintf("[%d/%d] %s on %s", k+1, len(jobs), j.Method, j.Instance)
+		if len(j.params) > 0 {
+			label = fmt.Sprintf("[%d/%d] %s with %v on %s", k+1, len(jobs), j.Method, j.params, j.Instance)
+		}
+		if !*rerun {
+			stored, err := experiment.LoadResults(filepath.Join(dir, "results.json"))
+			if err == nil && stored.Completed(j.Runs, manifest.Seed, j.params, j.Budget, j.problem, manifest.Trace) {
+				fmt.Printf("%s: complete, skipped\n", label)
+				skipped++
+				continue
+			}
+		}
+		if *dryRun {
+			fmt.Printf("%s: %d runs\n", label, j.Runs)
+			continue
+		}
+		fmt.Printf("%s: %d runs\n", label, j.Runs)
+
+		budget := j.Budget
+		if budget.Calibrate {
+			key := j.Instance + " " + budget.Kind.String()
+			if _, ok := calibrated[key]; !ok {
+				fmt.Printf("Calibrating the %v time budget on %d runs of %s\n", budget.Kind, manifest.CalibrationRuns, experiment.CalibrationMethod)
+				if calibrated[key], err = experiment.Calibrate(ctx, budget, j.problem, manifest.CalibrationRuns, manifest.Workers, batchSeed); err != nil {
+					log.Fatalf("Calibration failed: %v", err)
+				}
+			}
+			budget = calibrated[key]
+		}
+
+		runResults, err := experiment.Run(ctx, experiment.Config{
+			Method:  j.method,
+			Params:  j.params,
+			Problem: j.problem,
+			Budget:  budget,
+			Seed:    batchSeed,
+			Runs:    experiment.Indices(j.Runs),
+			Workers: manifest.Workers,
+			Strict:  manifest.Strict,
+			Trace:   manifest.Trace,
+		})
+		interrupted := errors.Is(err, context.Canceled)
+		if err != nil && !interrupted {
+			log.Fatalf("Aborting: %v", err)
+		}
+		if len(runResults) == 0 {
+			log.Fatalf("Interrupted before any run of %s was done", label)
+		}
+		results, err := experiment.Aggregate(j.method, j.params, batchSeed, runResults)
+		if err != nil {
+			log.Fatalf("Aborting %s: %v", label, err)
+		}
+		results.Interrupted = results.Interrupted || interrupted
+		selection, weights := j.problem.Selection, j.problem.Weights
+		results.Selection = &selection
+		results.Weights = &weights
+		if !budget.IsZero() {
+			results.Budget = &budget
+		}
+		if results.Traces != nil {
+			results.Anytime = experiment.NewAnytime(results.Traces, nil)
+		}
+		results.Metadata = experiment.NewMetadata(j.Instance, j.Runs, manifest.Workers)
+
+		var extra []string
+		if j.Variant != "" {
+			extra = append(extra, j.Variant)
+		}
+		path, err := sink.Write(j.Method, j.Instance, results, extra...)
+		if err != nil {
+			log.Fatalf("Error writing results: %v", err)
+		}
+		if results.Traces != nil {
+			if err := experiment.WriteTraces(filepath.Dir(path), results); err != nil {
+				log.Fatalf("Error writing traces: %v", err)
+			}
+		}
+		if instance := instances[j.Instance]; instance.HasCoordinates {
+			if err := experiment.WritePlots(filepath.Dir(path), instance, results); err != nil {
+				log.Fatalf("Error plotting solutions: %v", err)
+			}
+		}
+		fmt.Printf("Best fitness %d, average %.1f, worst %d, written to %s\n",
+			results.BestFitness, results.AverageFitness, results.WorstFitness, path)
+		if results.Interrupted {
+			fmt.Printf("Interrupted: %d of %d runs done, %d of them cut short, run the batch again to complete it\n",
+				len(runResults), j.Runs, len(results.InterruptedRuns))
+			return
+		}
+	}
+	if skipped > 0 {
+		fmt.Printf("%d of %d configurations were already complete\n", skipped, len(jobs))
+	}
+}
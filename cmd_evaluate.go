@@ -0,0 +1,95 @@
+package main
+
+import (
+	"evolutionary_computation/evaluate"
+	"evolutionary_computation/experiment"
+	"evolutionary_computation/utils"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+// evaluateCommand checks a single solution, or with --table every results.json in the logs tree.
+func evaluateCommand(args []string) {
+	flags := flag.NewFlagSet("evaluate", flag.ExitOnError)
+	table := flags.Bool("table", false, "re-evaluate the best solution of every results.json under --logs")
+	logsDir := flags.String("logs", "logs", "logs directory scanned with --table")
+	dataDir := flags.String("data", "data", "directory with the instances, for results without instance metadata")
+	out := flags.String("out", "fitness_comparison_table.csv", "CSV file written with --table")
+	edges := flags.Bool("edges", true, "print the per-edge breakdown")
+	weights := utils.UnitWeights
+	flags.IntVar(&weights.Alpha, "alpha", weights.Alpha, "weight of the length in the objective; defaults to the weights stored in results.json")
+	flags.IntVar(&weights.Beta, "beta", weights.Beta, "weight of the node costs in the objective; defaults to the weights stored in results.json")
+	selectSpec := flags.String("select", "", `nodes the solution selects, as for running a method; defaults to the selection stored in results.json, or "half"`)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go run . evaluate [flags] <instance> <solution>\n")
+		fmt.Fprintf(flags.Output(), "       go run . evaluate --table [--logs logs] [--out fitness_comparison_table.csv]\n\n")
+		fmt.Fprintf(flags.Output(), "The solution is a results.json, a JSON or plain list of node IDs, or a TSPLIB tour.\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if *table {
+		rows, err := evaluate.Table(*logsDir, *dataDir)
+		if err != nil {
+			log.Fatalf("Error evaluating %s: %v", *logsDir, err)
+		}
+		if err := evaluate.WriteTableFile(*out, rows); err != nil {
+			log.Fatalf("Error writing %s: %v", *out, err)
+		}
+		mismatches := 0
+		for _, row := range rows {
+			if !row.Match() {
+				mismatches++
+				fmt.Printf("Mismatch: %s on %s, stored %d, evaluated %d, feasible %v\n",
+					row.Method, row.Dataset, row.CalculatedFitness, row.EvaluatedFitness, row.Feasible)
+			}
+		}
+		fmt.Printf("Evaluated %d results, %d mismatches, table written to %s\n", len(rows), mismatches, *out)
+		return
+	}
+
+	if len(args) != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	inputFile, solutionFile := args[0], args[1]
+
+	instance, err := utils.LoadInstance(inputFile)
+	if err != nil {
+		log.Fatalf("Error loading instance from %s: %v", inputFile, err)
+	}
+	solution, err := utils.LoadSolution(solutionFile)
+	if err != nil {
+		log.Fatalf("Error loading solution: %v", err)
+	}
+
+	// Settings given on the command line take precedence over the ones stored in results.json
+	given := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	stored, storedErr := experiment.LoadResults(solutionFile)
+
+	selection := utils.HalfSelection(len(instance.Nodes))
+	if *selectSpec != "" {
+		if selection, err = utils.ParseSelection(*selectSpec, len(instance.Nodes)); err != nil {
+			log.Fatalf("Invalid --select: %v", err)
+		}
+	} else if storedErr == nil && stored.Selection != nil {
+		selection = *stored.Selection
+	}
+	if storedErr == nil && stored.Weights != nil {
+		if !given["alpha"] {
+			weights.Alpha = stored.Weights.Alpha
+		}
+		if !given["beta"] {
+			weights.Beta = stored.Weights.Beta
+		}
+	}
+
+	report := evaluate.Evaluate(instance, selection, weights, solution)
+	report.Print(os.Stdout, *edges)
+	if !report.Feasible() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"evolutionary_computation/generator"
+	"evolutionary_computation/solver"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// generateCommand writes a random instance, or a whole suite of them with a manifest.
+func generateCommand(args []string) {
+	spec := generator.DefaultSpec(0)
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	flags.IntVar(&spec.Nodes, "nodes", spec.Nodes, "number of nodes")
+	flags.IntVar(&spec.Width, "width", spec.Width, "width of the plane")
+	flags.IntVar(&spec.Height, "height", spec.Height, "height of the plane")
+	coordinates := flags.String("coords", spec.Coordinates, "coordinate distribution: "+strings.Join(generator.CoordinateKinds, ", ")+", comma separated for a suite")
+	flags.IntVar(&spec.Clusters, "clusters", spec.Clusters, "number of clusters of clustered coordinates")
+	costs := flags.String("costs", spec.Costs, "cost distribution: "+strings.Join(generator.CostKinds, ", ")+", comma separated for a suite")
+	flags.IntVar(&spec.MinCost, "min-cost", spec.MinCost, "lowest node cost")
+	flags.IntVar(&spec.MaxCost, "max-cost", spec.MaxCost, "highest node cost")
+	flags.Int64Var(&spec.Seed, "seed", solver.NewSeed(), "seed of the instance or suite, defaults to the current time")
+	suiteDir := flags.String("suite", "", "write a suite of instances of every combination of sizes, coordinates and costs into the directory")
+	sizes := flags.String("sizes", "100,200,500", "comma separated numbers of nodes of a suite")
+	replicates := flags.Int("replicates", 3, "instances of each combination of a suite")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go run . generate [flags] <instance.csv>\n")
+		fmt.Fprintf(flags.Output(), "       go run . generate --suite <dir> [flags]\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if *suiteDir == "" {
+		if len(args) != 1 {
+			flags.Usage()
+			os.Exit(2)
+		}
+		spec.Coordinates, spec.Costs = *coordinates, *costs
+		if err := generator.WriteFile(args[0], spec); err != nil {
+			log.Fatalf("Error generating instance: %v", err)
+		}
+		fmt.Printf("Instance written to %s (seed %d)\n", args[0], spec.Seed)
+		return
+	}
+
+	if len(args) != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	// A suite covers all distributions unless some are given
+	given := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	suite := generator.Suite{
+		Coordinates: generator.CoordinateKinds,
+		Costs:       generator.CostKinds,
+		Replicates:  *replicates,
+		Base:        spec,
+	}
+	if given["coords"] {
+		suite.Coordinates = strings.Split(*coordinates, ",")
+	}
+	if given["costs"] {
+		suite.Costs = strings.Split(*costs, ",")
+	}
+	for _, size := range strings.Split(*sizes, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(size))
+		if err != nil {
+			log.Fatalf("Invalid --sizes: %q is not a number", size)
+		}
+		suite.Sizes = append(suite.Sizes, n)
+	}
+
+	manifest, err := generator.WriteSuite(*suiteDir, suite)
+	if err != nil {
+		log.Fatalf("Error generating suite: %v", err)
+	}
+	fmt.Printf("%d instances and their manifest written to %s (seed %d)\n",
+		len(manifest.Instances), filepath.Join(*suiteDir, "manifest.json"), spec.Seed)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"evolutionary_computation/bound"
+	"evolutionary_computation/experiment"
+	"evolutionary_computation/methods"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"runtime"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Optimality is written by the optimality command: the optima of random subsets of an instance
+// and how far the methods are from them.
+type Optimality struct {
+	Nodes   int                 `json:"nodes"` // of each subset
+	Runs    int                 `json:"runs"`  // of each method on each subset
+	Seed    int64               `json:"seed"`
+	Samples []OptimalitySample  `json:"samples"`
+	Summary map[string]GapStats `json:"summary"` // over all samples, by method
+	// Selection of the subsets, half of their nodes when missing
+	Selection *utils.Selection `json:"selection,omitempty"`
+	// Interrupted marks gaps of an interrupted command, they cover the samples done until then
+	Interrupted bool                 `json:"interrupted,omitempty"`
+	Metadata    *experiment.Metadata `json:"metadata,omitempty"`
+}
+
+// OptimalitySample is one subset of the instance with its optimal solution, in subset numbering.
+type OptimalitySample struct {
+	Nodes    []int               `json:"nodes"` // of the instance, in the order they are numbered in the subset
+	Optimum  int                 `json:"optimum"`
+	Solution []int               `json:"solution"`
+	Methods  map[string]GapStats `json:"methods"`
+}
+
+// GapStats are relative gaps (fitness-optimum)/|optimum| of the runs of a method.
+// For a single sample Best, Average and Worst are the gaps of the runs, in the summary they are
+// the mean best and average gap and the largest worst gap over the samples.
+type GapStats struct {
+	Best    float64 `json:"best_gap"`
+	Average float64 `json:"average_gap"`
+	Worst   float64 `json:"worst_gap"`
+	Optimal int     `json:"optimal"` // runs reaching the optimum, in the summary over all samples
+}
+
+// optimalityCommand measures the optimality gaps of methods on random subsets of an instance
+// small enough to be solved exactly.
+func optimalityCommand(args []string) {
+	flags := flag.NewFlagSet("optimality", flag.ExitOnError)
+	numNodes := flags.Int("nodes", 16, fmt.Sprintf("nodes of each subset, at most %d; the exact solver keeps a table of 2^(nodes-1)*(nodes-1) 32-bit values, 176 MB for 22 nodes", methods.MaxExactNodes))
+	samples := flags.Int("samples", 10, "number of random subsets")
+	runs := flags.Int("runs", 20, "runs of each method on each subset")
+	seed := flags.Int64("seed", solver.NewSeed(), "seed of the subsets and runs, defaults to the current time")
+	timeLimit := flags.Duration("time", time.Second, "time limit of each run of a metaheuristic")
+	selectSpec := flags.String("select", "half", "nodes to select in each subset, as for running a method")
+	numWorkers := flags.Int("workers", runtime.NumCPU(), "number of runs performed concurrently")
+	logsDir := flags.String("logs", "logs", "directory the gaps are written to")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go run . optimality [flags] <instance> <method>[:name=value,...]...\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+	if len(args) < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	inputFile := args[0]
+
+	instance, err := utils.LoadInstance(inputFile)
+	if err != nil {
+		log.Fatalf("Error loading instance from %s: %v", inputFile, err)
+	}
+	if *numNodes > len(instance.Nodes) {
+		log.Fatalf("The instance has only %d nodes", len(instance.Nodes))
+	}
+
+	var names []string
+	solvers := make(map[string]solver.Solver)
+	params := make(map[string]solver.Params)
+	for _, spec := range args[1:] {
+		name, overrides, err := solver.ParseMethod(spec)
+		if err != nil {
+			log.Fatalf("Couldn't parse method: %v", err)
+		}
+		method, ok := solver.Lookup(name)
+		if !ok {
+			log.Fatalf("Unknown method: %s", name)
+		}
+		if params[name], err = solver.Resolve(method.Params(), overrides); err != nil {
+			log.Fatalf("Invalid parameters for %s: %v", name, err)
+		}
+		names = append(names, name)
+		solvers[name] = method
+	}
+
+	selection, err := utils.ParseSelection(*selectSpec, *numNodes)
+	if err != nil {
+		log.Fatalf("Invalid --select: %v", err)
+	}
+	result := Optimality{Nodes: *numNodes, Runs: *runs, Seed: *seed, Selection: &selection, Summary: make(map[string]GapStats)}
+	ctx := interruptible()
+samples:
+	for sample := 0; sample < *samples; sample++ {
+		sampleSeed := solver.DeriveSeed(*seed, sample)
+		nodes := solver.NewRng(sampleSeed).Perm(len(instance.Nodes))[:*numNodes]
+		sort.Ints(nodes)
+		problem, err := utils.NewProblem(instance.Subset(nodes), selection, utils.UnitWeights)
+		if err != nil {
+			log.Fatalf("Invalid problem: %v", err)
+		}
+
+		optimal, err := methods.Exact(ctx, problem)
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			log.Fatalf("Error solving subset %d: %v", sample, err)
+		}
+		s := OptimalitySample{Nodes: nodes, Optimum: problem.Fitness(optimal), Solution: optimal, Methods: make(map[string]GapStats)}
+
+		for _, name := range names {
+			runResults, err := experiment.Run(ctx, experiment.Config{
+				Method:  solvers[name],
+				Params:  params[name],
+				Problem: problem,
+				Budget:  solver.Budget{TimeLimit: *timeLimit},
+				Seed:    sampleSeed,
+				Runs:    experiment.Indices(*runs),
+				Workers: *numWorkers,
+				Strict:  true,
+			})
+			if ctx.Err() != nil {
+				break samples // the sample is left out with some of its runs missing
+			}
+			if err != nil {
+				log.Fatalf("Aborting: %v", err)
+			}
+			s.Methods[name] = gapStats(runResults, s.Optimum)
+		}
+		result.Samples = append(result.Samples, s)
+		fmt.Printf("Subset %d: optimum %d\n", sample, s.Optimum)
+	}
+
+	result.Interrupted = ctx.Err() != nil
+	if len(result.Samples) == 0 {
+		log.Fatalf("Interrupted before any subset was done")
+	}
+	if result.Interrupted {
+		fmt.Printf("Interrupted: %d of %d subsets done\n", len(result.Samples), *samples)
+	}
+
+	for _, name := range names {
+		var summary GapStats
+		for _, s := range result.Samples {
+			gaps := s.Methods[name]
+			summary.Best += gaps.Best / float64(len(result.Samples))
+			summary.Average += gaps.Average / float64(len(result.Samples))
+			summary.Worst = max(summary.Worst, gaps.Worst)
+			summary.Optimal += gaps.Optimal
+		}
+		result.Summary[name] = summary
+	}
+	result.Metadata = experiment.NewMetadata(inputFile, *runs, *numWorkers)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "\nmethod\tbest gap\taverage gap\tworst gap\toptimal runs\n")
+	for _, name := range names {
+		gaps := result.Summary[name]
+		fmt.Fprintf(tw, "%s\t%.2f%%\t%.2f%%\t%.2f%%\t%d of %d\n", name, 100*gaps.Best, 100*gaps.Average, 100*gaps.Worst, gaps.Optimal, *runs*len(result.Samples))
+	}
+	tw.Flush()
+
+	sink := experiment.Sink{Root: *logsDir}
+	path, err := sink.Write("optimality", inputFile, result)
+	if err != nil {
+		log.Fatalf("Error writing results: %v", err)
+	}
+	fmt.Printf("Results written to %s\n", path)
+}
+
+// gapStats returns the gaps of the runs to the optimum, the same way as to a lower bound.
+func gapStats(runs []experiment.RunResult, optimum int) GapStats {
+	stats := GapStats{Best: math.Inf(1), Worst: math.Inf(-1)}
+	for _, run := range runs {
+		g := bound.Gap(run.Fitness, optimum)
+		stats.Best = math.Min(stats.Best, g)
+		stats.Worst = math.Max(stats.Worst, g)
+		stats.Average += g / float64(len(runs))
+		if run.Fitness == optimum {
+			stats.Optimal++
+		}
+	}
+	return stats
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"evolutionary_computation/experiment"
+	"evolutionary_computation/methods/local_search"
+	"evolutionary_computation/pareto"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// paretoCommand approximates the front of cycle length versus node cost with Pareto local search,
+// or compares two fronts written by it.
+func paretoCommand(args []string) {
+	flags := flag.NewFlagSet("pareto", flag.ExitOnError)
+	compare := flags.Bool("compare", false, "compare two fronts instead of computing one")
+	seed := flags.Int64("seed", solver.NewSeed(), "seed of the search, defaults to the current time")
+	timeLimit := flags.Duration("time", 30*time.Second, "time limit of the search")
+	numWeights := flags.Int("weights", local_search.ParetoParams[0].Default.(int), local_search.ParetoParams[0].Usage)
+	selectSpec := flags.String("select", "half", "nodes to select, as for running a method")
+	logsDir := flags.String("logs", "logs", "directory the front is written to")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go run . pareto [flags] <instance>\n")
+		fmt.Fprintf(flags.Output(), "       go run . pareto --compare <results.json> <results.json>\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if *compare {
+		if len(args) != 2 {
+			flags.Usage()
+			os.Exit(2)
+		}
+		var fronts [2]pareto.Front
+		for i, file := range args {
+			front, err := pareto.LoadFront(file)
+			if err != nil {
+				log.Fatalf("Error loading front: %v", err)
+			}
+			fronts[i] = front
+		}
+		pareto.Compare(fronts[0], fronts[1]).Print(os.Stdout)
+		return
+	}
+
+	if len(args) != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	inputFile := args[0]
+
+	instance, err := utils.LoadInstance(inputFile)
+	if err != nil {
+		log.Fatalf("Error loading instance from %s: %v", inputFile, err)
+	}
+	selection, err := utils.ParseSelection(*selectSpec, len(instance.Nodes))
+	if err != nil {
+		log.Fatalf("Invalid --select: %v", err)
+	}
+	problem, err := utils.NewProblem(instance, selection, utils.UnitWeights)
+	if err != nil {
+		log.Fatalf("Invalid problem: %v", err)
+	}
+	params, err := solver.Resolve(local_search.ParetoParams, map[string]string{"weights": strconv.Itoa(*numWeights)})
+	if err != nil {
+		log.Fatalf("Invalid parameters: %v", err)
+	}
+
+	fmt.Printf("Running Pareto local search for %v with parameters: %v\n", *timeLimit, params)
+	runSeed := solver.DeriveSeed(*seed, 0)
+	ctx := interruptible()
+	archive, evaluations := local_search.ParetoLocalSearch(ctx, solver.Run{
+		Problem: problem,
+		Params:  params,
+		Budget:  solver.Budget{TimeLimit: *timeLimit},
+		Seed:    runSeed,
+		Rng:     solver.NewRng(runSeed),
+	})
+
+	front := pareto.NewFront("pareto", params, *seed, archive)
+	front.Evaluations = evaluations
+	front.Interrupted = ctx.Err() != nil
+	front.Metadata = experiment.NewMetadata(inputFile, 1, 1)
+
+	sink := experiment.Sink{Root: *logsDir}
+	path, err := sink.Write("pareto", inputFile, front)
+	if err != nil {
+		log.Fatalf("Error writing results: %v", err)
+	}
+
+	b := front.Bounds
+	fmt.Printf("Front of %d solutions, length %d to %d, node cost %d to %d\n", len(front.Points), b.MinLength, b.MaxLength, b.MinCost, b.MaxCost)
+	fmt.Printf("Hypervolume: %.4f, spacing: %.4f, spread: %.4f (normalized)\n", front.Hypervolume, front.Spacing, front.Spread)
+	fmt.Printf("Results written to %s\n", path)
+}
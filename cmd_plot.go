@@ -0,0 +1,49 @@
+package main
+
+import (
+	"evolutionary_computation/experiment"
+	"evolutionary_computation/utils"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// plotCommand renders the best and worst solution of a results.json as SVG images.
+func plotCommand(args []string) {
+	flags := flag.NewFlagSet("plot", flag.ExitOnError)
+	outDir := flags.String("out", "", "directory the images are written to, defaults to the directory of results.json")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go run . plot [flags] <instance> <results.json>\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	args = parseInterspersed(flags, args)
+	if len(args) != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	inputFile, resultsFile := args[0], args[1]
+
+	instance, err := utils.LoadInstance(inputFile)
+	if err != nil {
+		log.Fatalf("Error loading instance from %s: %v", inputFile, err)
+	}
+	results, err := experiment.LoadResults(resultsFile)
+	if err != nil {
+		log.Fatalf("Error loading results from %s: %v", resultsFile, err)
+	}
+
+	dir := *outDir
+	if dir == "" {
+		dir = filepath.Dir(resultsFile)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Fatalf("Error creating %s: %v", dir, err)
+	}
+	if err := experiment.WritePlots(dir, instance, results); err != nil {
+		log.Fatalf("Error plotting solutions: %v", err)
+	}
+	fmt.Printf("Plots written to %s\n", dir)
+}
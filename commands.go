@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+)
+
+// commands are the subcommands accepted instead of a data file as the first argument.
+var commands = map[string]func(args []string){
+	"batch":      batchCommand,
+	"evaluate":   evaluateCommand,
+	"generate":   generateCommand,
+	"optimality": optimalityCommand,
+	"pareto":     paretoCommand,
+	"plot":       plotCommand,
+}
+
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// interruptible returns a context cancelled by the first SIGINT or SIGTERM, so that the runs stop
+// with their best solution so far and the results are still written. A second signal kills the program.
+func interruptible() context.Context {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+		fmt.Fprintln(os.Stderr, "Interrupted, stopping the runs in progress (interrupt again to quit)")
+	}()
+	return ctx
+}
@@ -0,0 +1,92 @@
+package evaluate
+
+import (
+	"evolutionary_computation/utils"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Edge is one edge of a cycle together with what it contributes to the fitness.
+type Edge struct {
+	From, To int
+	Length   int // distance in the matrix of the instance, given or computed from the coordinates
+	Cost     int // cost of the node the edge leads to
+}
+
+// Report breaks the fitness of a solution down into the length of the cycle and the node costs.
+type Report struct {
+	Solution []int
+	Edges    []Edge
+	Length   int
+	NodeCost int
+	Weights  utils.Weights
+	Total    int   // the objective, Weights applied to Length and NodeCost
+	Err      error // why the solution is infeasible, nil if it is feasible
+}
+
+// Evaluate computes the fitness of the solution directly from the instance and checks its feasibility.
+// Infeasible solutions are still evaluated as long as all their node IDs are in range.
+func Evaluate(instance *utils.Instance, selection utils.Selection, weights utils.Weights, solution []int) Report {
+	nodes := instance.Nodes
+	report := Report{
+		Solution: solution,
+		Weights:  weights,
+		Err:      utils.ValidateSolution(solution, len(nodes), selection),
+	}
+
+	for _, node := range solution {
+		if node < 0 || node >= len(nodes) {
+			return report
+		}
+	}
+
+	for i := range solution {
+		from, to := solution[i], solution[(i+1)%len(solution)]
+		edge := Edge{
+			From:   from,
+			To:     to,
+			Length: instance.Distances[from][to],
+			Cost:   nodes[to].Cost,
+		}
+		report.Edges = append(report.Edges, edge)
+		report.Length += edge.Length
+		report.NodeCost += edge.Cost
+	}
+	report.Total = weights.Objective(report.Length, report.NodeCost)
+
+	return report
+}
+
+// Feasible tells whether the solution passed the feasibility checks.
+func (r Report) Feasible() bool {
+	return r.Err == nil
+}
+
+// Print writes the summary of the report, followed by the per-edge breakdown when edges is set.
+func (r Report) Print(w io.Writer, edges bool) {
+	if r.Feasible() {
+		fmt.Fprintf(w, "Feasible: yes (%d nodes)\n", len(r.Solution))
+	} else {
+		fmt.Fprintf(w, "Feasible: no, %v\n", r.Err)
+	}
+	fmt.Fprintf(w, "Edge length: %d\nNode cost: %d\n", r.Length, r.NodeCost)
+	if r.Weights == utils.UnitWeights {
+		fmt.Fprintf(w, "Total cost: %d\n", r.Total)
+	} else {
+		fmt.Fprintf(w, "Total cost: %d*%d + %d*%d = %d\n", r.Weights.Alpha, r.Length, r.Weights.Beta, r.NodeCost, r.Total)
+	}
+
+	if !edges || len(r.Edges) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "from\tto\tlength\tnode cost\tcumulative\t")
+	cumulative := 0
+	for _, edge := range r.Edges {
+		cumulative += r.Weights.Objective(edge.Length, edge.Cost)
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t\n", edge.From, edge.To, edge.Length, edge.Cost, cumulative)
+	}
+	tw.Flush()
+}
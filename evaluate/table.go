@@ -0,0 +1,138 @@
+package evaluate
+
+import (
+	"encoding/csv"
+	"evolutionary_computation/experiment"
+	"evolutionary_computation/utils"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Row compares the best fitness stored in one results.json with a fresh evaluation of the best solution.
+type Row struct {
+	Method            string
+	Dataset           string
+	CalculatedFitness int
+	EvaluatedFitness  int
+	Feasible          bool
+}
+
+// Match tells whether the stored fitness agrees with the evaluation.
+func (r Row) Match() bool {
+	return r.Feasible && r.CalculatedFitness == r.EvaluatedFitness
+}
+
+// Table re-evaluates the best solution of every results.json under root.
+// The instance of a result is taken from its metadata, or else looked up as
+// <dataDir>/<instance directory>.csv.
+func Table(root, dataDir string) ([]Row, error) {
+	var rows []Row
+	instances := make(map[string]*utils.Instance)
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || d.Name() != "results.json" {
+			return err
+		}
+		results, err := experiment.LoadResults(path)
+		if err != nil || results.BestSolution == nil {
+			return nil // not the results of a method run, e.g. global convexity
+		}
+
+		dataset := filepath.Base(filepath.Dir(path))
+		instancePath := filepath.Join(dataDir, dataset+".csv")
+		if results.Metadata != nil && results.Metadata.Instance != "" {
+			// Batches keep their results in subdirectories of the instance directory
+			instancePath = results.Metadata.Instance
+			dataset = experiment.InstanceName(instancePath)
+		}
+		instance, ok := instances[instancePath]
+		if !ok {
+			if instance, err = utils.LoadInstance(instancePath); err != nil {
+				return err
+			}
+			instances[instancePath] = instance
+		}
+
+		method := results.Method
+		if method == "" {
+			method = filepath.Base(filepath.Dir(filepath.Dir(path)))
+		}
+		selection := utils.HalfSelection(len(instance.Nodes))
+		if results.Selection != nil {
+			selection = *results.Selection
+		}
+		weights := utils.UnitWeights
+		if results.Weights != nil {
+			weights = *results.Weights
+		}
+		report := Evaluate(instance, selection, weights, results.BestSolution)
+		rows = append(rows, Row{
+			Method:            method,
+			Dataset:           dataset,
+			CalculatedFitness: results.BestFitness,
+			EvaluatedFitness:  report.Total,
+			Feasible:          report.Feasible(),
+		})
+		return nil
+	})
+
+	sort.SliceStable(rows, func(a, b int) bool {
+		if rows[a].Method != rows[b].Method {
+			return rows[a].Method < rows[b].Method
+		}
+		return rows[a].Dataset < rows[b].Dataset
+	})
+	return rows, err
+}
+
+// WriteTable writes the rows in the format of fitness_comparison_table.csv.
+func WriteTable(w io.Writer, rows []Row) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"method", "dataset", "calculated_fitness", "evaluated_fitness", "feasible", "match"})
+	for _, row := range rows {
+		out.Write([]string{
+			displayName(row.Method),
+			row.Dataset,
+			strconv.Itoa(row.CalculatedFitness),
+			strconv.Itoa(row.EvaluatedFitness),
+			strconv.FormatBool(row.Feasible),
+			boolTitle(row.Match()),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteTableFile writes the rows to a CSV file.
+func WriteTableFile(path string, rows []Row) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteTable(file, rows); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// displayName turns a method name such as greedy_cycle into "Greedy Cycle", as in the existing table.
+func displayName(method string) string {
+	words := strings.Fields(strings.ReplaceAll(method, "_", " "))
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+func boolTitle(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
@@ -0,0 +1,51 @@
+package experiment
+
+import (
+	"context"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"fmt"
+	"time"
+)
+
+// CalibrationMethod is the method whose mean runtime on an instance is the time limit of a
+// calibrated budget, so that metaheuristics get as much time as multi-start local search takes.
+const CalibrationMethod = "LS_multi"
+
+// Calibrate returns the budget with its time limit set to the mean wall-clock or CPU time of
+// runs of CalibrationMethod with its default parameters on the problem.
+// Budgets that don't ask for calibration are returned as they are.
+func Calibrate(ctx context.Context, budget solver.Budget, problem *utils.Problem, runs, workers int, seed int64) (solver.Budget, error) {
+	if !budget.Calibrate || !budget.IsZero() {
+		return budget, nil
+	}
+	method, ok := solver.Lookup(CalibrationMethod)
+	if !ok {
+		return budget, fmt.Errorf("calibration method %s is not registered", CalibrationMethod)
+	}
+	runResults, err := Run(ctx, Config{
+		Method:  method,
+		Params:  solver.Defaults(method.Params()),
+		Problem: problem,
+		Seed:    seed,
+		Runs:    Indices(runs),
+		Workers: workers,
+	})
+	if err != nil {
+		return budget, err
+	}
+
+	var total float64
+	for _, run := range runResults {
+		if budget.Kind == solver.CPUTime {
+			total += run.CPUTime
+		} else {
+			total += run.WallTime
+		}
+	}
+	if total == 0 {
+		return budget, fmt.Errorf("calibration measured no %v time, it may be unavailable on this platform", budget.Kind)
+	}
+	budget.TimeLimit = time.Duration(total / float64(len(runResults)) * float64(time.Second))
+	return budget, nil
+}
@@ -0,0 +1,64 @@
+package experiment_test
+
+import (
+	"context"
+	"evolutionary_computation/experiment"
+	_ "evolutionary_computation/methods/local_search" // registers the calibration method
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"testing"
+)
+
+func TestCalibrate(t *testing.T) {
+	instance, err := utils.LoadInstance("../data/TSPA.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := make([]int, 30)
+	for i := range nodes {
+		nodes[i] = 6 * i
+	}
+	problem, err := utils.NewProblem(instance.Subset(nodes), utils.HalfSelection(len(nodes)), utils.UnitWeights)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		spec      string
+		calibrate bool // a time limit is measured, other budgets are returned as they are
+	}{
+		{"calibrate", true},
+		{"calibrate:cpu", true},
+		{"wall:2s", false},
+		{"evals:100", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			requested, err := solver.ParseBudget(test.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			budget, err := experiment.Calibrate(context.Background(), requested, problem, 2, 2, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !test.calibrate {
+				if budget != requested {
+					t.Fatalf("Calibrate changed %v to %v", requested, budget)
+				}
+				return
+			}
+			if budget.Kind != requested.Kind || !budget.Calibrate || budget.TimeLimit <= 0 {
+				t.Fatalf("Calibrate(%v) = %+v, expected a positive %v limit", requested, budget, requested.Kind)
+			}
+			if !budget.Matches(requested) {
+				t.Fatalf("calibrated %v doesn't match the requested %v", budget, requested)
+			}
+			// A measured budget is kept as it is
+			if again, err := experiment.Calibrate(context.Background(), budget, problem, 2, 2, 1); err != nil || again != budget {
+				t.Fatalf("calibrating %v again gives %v, %v", budget, again, err)
+			}
+		})
+	}
+}
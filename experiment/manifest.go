@@ -0,0 +1,182 @@
+package experiment
+
+import (
+	"encoding/json"
+	"evolutionary_computation/solver"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Manifest declares a batch of experiments: every method with every combination of the values
+// of its parameter grid, on every instance. For example
+//
+//	{
+//	    "instances": ["data/TSPA.csv", "data/TSPB.csv"],
+//	    "methods": [
+//	        {"name": "greedy_cycle"},
+//	        {"name": "custom", "grid": {"temperature": [500, 800], "tenure": [20, 30]}, "runs": 20}
+//	    ],
+//	    "runs": 200,
+//	    "seed": 42,
+//	    "budget": "calibrate"
+//	}
+type Manifest struct {
+	Instances []string         `json:"instances"`
+	Methods   []ManifestMethod `json:"methods"`
+	Runs      int              `json:"runs,omitempty"` // of each configuration, 200 when missing
+	// Seed of every configuration, so all methods start from the same run seeds.
+	// When missing a new seed is drawn and completed results are recognized regardless of their seed.
+	Seed int64 `json:"seed,omitempty"`
+	// Budget of each run of a metaheuristic, see solver.ParseBudget, their own default when missing.
+	// A calibrated budget is measured once per instance.
+	Budget          string `json:"budget,omitempty"`
+	CalibrationRuns int    `json:"calibration_runs,omitempty"` // runs of LS_multi measured, 5 when missing
+	Select          string `json:"select,omitempty"`           // see utils.ParseSelection, "half" when missing
+	Alpha           *int   `json:"alpha,omitempty"`            // weight of the length, 1 when missing
+	Beta            *int   `json:"beta,omitempty"`             // weight of the node costs, 1 when missing
+	Workers         int    `json:"workers,omitempty"`
+	Strict          bool   `json:"strict,omitempty"`
+	Trace           bool   `json:"trace,omitempty"` // record the convergence of every run, see Config.Trace
+	Logs            string `json:"logs,omitempty"`  // "logs" when missing
+}
+
+// ManifestMethod is a method of a manifest with the values of its parameters to try.
+// Runs and Budget override the ones of the manifest.
+type ManifestMethod struct {
+	Name   string                       `json:"name"`
+	Grid   map[string][]json.RawMessage `json:"grid,omitempty"`
+	Runs   int                          `json:"runs,omitempty"`
+	Budget string                       `json:"budget,omitempty"`
+}
+
+// Configuration is one method with one set of parameter values on one instance.
+type Configuration struct {
+	Instance  string
+	Method    string
+	Overrides map[string]string // the values of the grid, the other parameters keep their defaults
+	Runs      int
+	Budget    solver.Budget
+	// Variant names the values of the grid, the results of the configuration are written into
+	// a directory of that name under the directory of the method and instance. Empty without a grid.
+	Variant string
+}
+
+// LoadManifest reads a manifest and fills in the defaults.
+func LoadManifest(filename string) (Manifest, error) {
+	var m Manifest
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(bytes, &m); err != nil {
+		return m, fmt.Errorf("%s: %w", filename, err)
+	}
+	if len(m.Instances) == 0 || len(m.Methods) == 0 {
+		return m, fmt.Errorf("%s: no instances or no methods", filename)
+	}
+	// The results of an instance go to a directory named after its file only
+	paths := make(map[string]string, len(m.Instances))
+	for _, instance := range m.Instances {
+		name := InstanceName(instance)
+		if other, ok := paths[name]; ok {
+			return m, fmt.Errorf("%s: instances %s and %s would write their results to the same %s directory", filename, other, instance, name)
+		}
+		paths[name] = instance
+	}
+	if m.Runs == 0 {
+		m.Runs = 200
+	}
+	if m.CalibrationRuns == 0 {
+		m.CalibrationRuns = 5
+	}
+	if m.Select == "" {
+		m.Select = "half"
+	}
+	if m.Alpha == nil {
+		m.Alpha = new(int)
+		*m.Alpha = 1
+	}
+	if m.Beta == nil {
+		m.Beta = new(int)
+		*m.Beta = 1
+	}
+	if *m.Alpha < 0 || *m.Beta < 0 || *m.Alpha+*m.Beta == 0 {
+		return m, fmt.Errorf("%s: invalid weights alpha=%d, beta=%d, expected non-negative and not both 0", filename, *m.Alpha, *m.Beta)
+	}
+	if m.Logs == "" {
+		m.Logs = "logs"
+	}
+	return m, nil
+}
+
+// Configurations expands the manifest, instance by instance, in the order the methods are listed.
+func (m Manifest) Configurations() ([]Configuration, error) {
+	var configs []Configuration
+	for _, instance := range m.Instances {
+		for _, method := range m.Methods {
+			runs, spec := m.Runs, m.Budget
+			if method.Runs > 0 {
+				runs = method.Runs
+			}
+			if method.Budget != "" {
+				spec = method.Budget
+			}
+			budget, err := solver.ParseBudget(spec)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", method.Name, err)
+			}
+			for _, overrides := range method.combinations() {
+				configs = append(configs, Configuration{
+					Instance:  instance,
+					Method:    method.Name,
+					Overrides: overrides,
+					Runs:      runs,
+					Budget:    budget,
+					Variant:   variant(overrides),
+				})
+			}
+		}
+	}
+	return configs, nil
+}
+
+// combinations returns every combination of the values of the grid, varying the last parameter
+// in alphabetical order first.
+func (m ManifestMethod) combinations() []map[string]string {
+	names := make([]string, 0, len(m.Grid))
+	for name := range m.Grid {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	combinations := []map[string]string{{}}
+	for _, name := range names {
+		var next []map[string]string
+		for _, combination := range combinations {
+			for _, raw := range m.Grid[name] {
+				var value string
+				if err := json.Unmarshal(raw, &value); err != nil {
+					value = string(raw) // numbers are kept as written in the file
+				}
+				extended := map[string]string{name: value}
+				for k, v := range combination {
+					extended[k] = v
+				}
+				next = append(next, extended)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+func variant(overrides map[string]string) string {
+	pairs := make([]string, 0, len(overrides))
+	for name, value := range overrides {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
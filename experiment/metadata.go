@@ -0,0 +1,51 @@
+package experiment
+
+import (
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Metadata describes the environment an experiment was run in.
+type Metadata struct {
+	Timestamp   string `json:"timestamp"`
+	Instance    string `json:"instance"`
+	Iterations  int    `json:"iterations"`
+	Workers     int    `json:"workers"`
+	GoVersion   string `json:"go_version"`
+	NumCPU      int    `json:"num_cpu"`
+	GitRevision string `json:"git_revision,omitempty"`
+}
+
+// NewMetadata collects the metadata of an experiment started now.
+func NewMetadata(instancePath string, iterations, workers int) *Metadata {
+	return &Metadata{
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Instance:    instancePath,
+		Iterations:  iterations,
+		Workers:     workers,
+		GoVersion:   runtime.Version(),
+		NumCPU:      runtime.NumCPU(),
+		GitRevision: gitRevision(),
+	}
+}
+
+// gitRevision returns the revision the binary was built from, or the revision of the
+// working directory when running with go run. It is empty when neither is available.
+func gitRevision() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
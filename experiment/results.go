@@ -0,0 +1,239 @@
+package experiment
+
+import (
+	"encoding/json"
+	"evolutionary_computation/bound"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"fmt"
+	"math"
+	"os"
+)
+
+// Results summarizes all runs of a method on one instance.
+type Results struct {
+	Method string        `json:"method"`
+	Params solver.Params `json:"params"`
+	// Selection is the number of nodes the solutions select, half of the nodes when missing
+	Selection *utils.Selection `json:"selection,omitempty"`
+	// Weights of the objective alpha*length + beta*cost, both 1 when missing
+	Weights *utils.Weights `json:"weights,omitempty"`
+	// Budget of each run of a metaheuristic, missing when the methods used their defaults
+	Budget         *solver.Budget `json:"budget,omitempty"`
+	BestSolution   []int          `json:"best_solution"`
+	BestFitness    int            `json:"best_fitness"`
+	WorstSolution  []int          `json:"worst_solution"`
+	WorstFitness   int            `json:"worst_fitness"`
+	AverageFitness float32        `json:"average_fitness"`
+	// The fitness split into the length of the cycle and the costs of its nodes, before weighting
+	BestLength      int       `json:"best_length"`
+	BestNodeCost    int       `json:"best_node_cost"`
+	WorstLength     int       `json:"worst_length"`
+	WorstNodeCost   int       `json:"worst_node_cost"`
+	AverageLength   float32   `json:"average_length"`
+	AverageNodeCost float32   `json:"average_node_cost"`
+	ExecutionTime   []float64 `json:"execution_time"` // wall time in seconds
+	CPUTime         []float64 `json:"cpu_time"`       // in seconds, zeros where the platform can't measure it
+	// Counters of the work done in each run
+	Counters       []utils.Counters `json:"counters"`
+	Seed           int64            `json:"seed"`
+	BestRun        int              `json:"best_run"`
+	BestSeed       int64            `json:"best_seed"`
+	WorstRun       int              `json:"worst_run"`
+	WorstSeed      int64            `json:"worst_seed"`
+	RunSeeds       []int64          `json:"run_seeds"`
+	ViolationCount int              `json:"violation_count"`
+	Violations     []Violation      `json:"violations,omitempty"`
+	Gap            *Gap             `json:"gap,omitempty"`
+	// Interrupted marks results of an experiment that was stopped before all its runs were done,
+	// they hold the runs performed, the ones cut short are listed in InterruptedRuns
+	Interrupted     bool  `json:"interrupted,omitempty"`
+	InterruptedRuns []int `json:"interrupted_runs,omitempty"`
+	// Anytime summarizes the convergence of traced runs
+	Anytime  *Anytime  `json:"anytime,omitempty"`
+	Traces   []Trace   `json:"-"` // of traced runs, written separately by WriteTraces
+	Metadata *Metadata `json:"metadata,omitempty"`
+}
+
+// Violation records a run that returned an infeasible solution.
+type Violation struct {
+	Run   int    `json:"run"`
+	Seed  int64  `json:"seed"`
+	Error string `json:"error"`
+}
+
+// Gap relates the best, average and worst fitness to a lower bound on the optimum,
+// each as (fitness - bound) / bound.
+type Gap struct {
+	LowerBound bound.Bound `json:"lower_bound"`
+	Best       float64     `json:"best"`
+	Average    float64     `json:"average"`
+	Worst      float64     `json:"worst"`
+}
+
+// NewGap returns the gaps of the results to the lower bound.
+func NewGap(results Results, lower bound.Bound) *Gap {
+	return &Gap{
+		LowerBound: lower,
+		Best:       bound.Gap(results.BestFitness, lower.Value),
+		Average:    float64(results.AverageFitness-float32(lower.Value)) / math.Max(1, math.Abs(float64(lower.Value))),
+		Worst:      bound.Gap(results.WorstFitness, lower.Value),
+	}
+}
+
+// RunResult is the outcome of a single run together with how it was started.
+type RunResult struct {
+	Index     int
+	Seed      int64
+	StartNode int
+	solver.Result
+	Length   int // of the cycle of the solution
+	NodeCost int // of the nodes of the solution
+	Counters utils.Counters
+	// Interrupted tells that the run was cancelled, its solution is the best one found until then
+	Interrupted bool
+	WallTime    float64 // in seconds
+	CPUTime     float64 // in seconds
+	Violation   error   // why the solution is infeasible, nil if it is feasible
+}
+
+// Aggregate summarizes the runs in the order they are given.
+// The outcome doesn't depend on the order in which the runs finished. Runs with infeasible
+// solutions are only counted as violations, they take no part in the best, worst and average
+// solutions. It is an error when no run is feasible.
+func Aggregate(method solver.Solver, params solver.Params, seed int64, runs []RunResult) (Results, error) {
+	var bestFitness, worstFitness, totalFitness, feasible int
+	var totalLength, totalNodeCost int
+	var best, worst RunResult
+	var bestSolution, worstSolution []int
+	var bestRun, worstRun int
+	times := make([]float64, 0, len(runs))
+	cpuTimes := make([]float64, 0, len(runs))
+	counters := make([]utils.Counters, 0, len(runs))
+	runSeeds := make([]int64, 0, len(runs))
+	var violations []Violation
+	var traces []Trace
+	var interrupted []int
+
+	for _, run := range runs {
+		if run.Interrupted {
+			interrupted = append(interrupted, run.Index)
+		}
+		if run.Trace != nil {
+			traces = append(traces, Trace{Run: run.Index, Points: run.Trace})
+		}
+		times = append(times, run.WallTime)
+		cpuTimes = append(cpuTimes, run.CPUTime)
+		counters = append(counters, run.Counters)
+		runSeeds = append(runSeeds, run.Seed)
+		if run.Violation != nil {
+			violations = append(violations, Violation{Run: run.Index, Seed: run.Seed, Error: run.Violation.Error()})
+			continue
+		}
+
+		feasible++
+		if feasible == 1 || run.Fitness < bestFitness {
+			bestFitness = run.Fitness
+			bestSolution = run.Solution
+			bestRun = run.Index
+			best = run
+		}
+		if feasible == 1 || run.Fitness > worstFitness {
+			worstFitness = run.Fitness
+			worstSolution = run.Solution
+			worstRun = run.Index
+			worst = run
+		}
+		totalFitness += run.Fitness
+		totalLength += run.Length
+		totalNodeCost += run.NodeCost
+	}
+	if feasible == 0 {
+		return Results{}, fmt.Errorf("all %d runs returned infeasible solutions, run %d: %s", len(runs), violations[0].Run, violations[0].Error)
+	}
+
+	return Results{
+		Method:          method.Name(),
+		Params:          params,
+		BestSolution:    bestSolution,
+		BestFitness:     bestFitness,
+		WorstSolution:   worstSolution,
+		WorstFitness:    worstFitness,
+		AverageFitness:  float32(totalFitness) / float32(feasible),
+		BestLength:      best.Length,
+		BestNodeCost:    best.NodeCost,
+		WorstLength:     worst.Length,
+		WorstNodeCost:   worst.NodeCost,
+		AverageLength:   float32(totalLength) / float32(feasible),
+		AverageNodeCost: float32(totalNodeCost) / float32(feasible),
+		ExecutionTime:   times,
+		CPUTime:         cpuTimes,
+		Counters:        counters,
+		Seed:            seed,
+		BestRun:         bestRun,
+		BestSeed:        solver.DeriveSeed(seed, bestRun),
+		WorstRun:        worstRun,
+		WorstSeed:       solver.DeriveSeed(seed, worstRun),
+		RunSeeds:        runSeeds,
+		ViolationCount:  len(violations),
+		Violations:      violations,
+		Traces:          traces,
+		Interrupted:     len(interrupted) > 0,
+		InterruptedRuns: interrupted,
+	}, nil
+}
+
+// Work is the mean work done by a run, the delta evaluations and moves of all kinds together.
+type Work struct {
+	Fitness, Deltas, Moves, LocalSearches, Iterations float64
+}
+
+// AverageWork returns the mean of the counters of the runs.
+func (r Results) AverageWork() Work {
+	var average Work
+	if len(r.Counters) == 0 {
+		return average
+	}
+	n := float64(len(r.Counters))
+	for _, c := range r.Counters {
+		average.Fitness += float64(c.Fitness) / n
+		average.Deltas += float64(c.TotalDeltas()) / n
+		average.Moves += float64(c.TotalMoves()) / n
+		average.LocalSearches += float64(c.LocalSearches) / n
+		average.Iterations += float64(c.Iterations) / n
+	}
+	return average
+}
+
+// LoadResults reads a results.json written by the Sink.
+func LoadResults(filename string) (Results, error) {
+	var results Results
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		return results, err
+	}
+	err = json.Unmarshal(bytes, &results)
+	return results, err
+}
+
+// Completed tells whether the results hold all runs of the configuration with the given
+// parameters and budget on the problem, traced or not, so that a resumed batch can skip it.
+// A zero seed matches any seed. Interrupted results are never complete.
+func (r Results) Completed(runs int, seed int64, params solver.Params, budget solver.Budget, problem *utils.Problem, trace bool) bool {
+	if r.Interrupted || len(r.RunSeeds) != runs || (seed != 0 && r.Seed != seed) {
+		return false
+	}
+	stored := solver.Budget{}
+	if r.Budget != nil {
+		stored = *r.Budget
+	}
+	selection, weights := utils.HalfSelection(problem.NumNodes()), utils.UnitWeights
+	if r.Selection != nil {
+		selection = *r.Selection
+	}
+	if r.Weights != nil {
+		weights = *r.Weights
+	}
+	return r.Params.Equal(params) && stored.Matches(budget) &&
+		selection == problem.Selection && weights == problem.Weights && (r.Anytime != nil) == trace
+}
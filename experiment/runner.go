@@ -0,0 +1,160 @@
+package experiment
+
+import (
+	"context"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"fmt"
+	"log"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Config describes the runs of one method on one instance.
+type Config struct {
+	Method  solver.Solver
+	Params  solver.Params
+	Problem *utils.Problem
+	Budget  solver.Budget // passed to every run, the zero value leaves the limits to the method
+	Seed    int64
+	Runs    []int // indices of the runs to perform, see Indices
+	Workers int   // number of runs performed concurrently, at least 1
+	// Strict aborts the experiment on the first infeasible solution,
+	// otherwise infeasible solutions are only recorded as violations.
+	Strict bool
+	// Trace records the progress of every run, see RunResult.Trace
+	Trace bool
+}
+
+// Indices returns the indices of the runs 0, 1, ..., iterations-1.
+func Indices(iterations int) []int {
+	runs := make([]int, iterations)
+	for i := range runs {
+		runs[i] = i
+	}
+	return runs
+}
+
+// Run performs all runs of the configuration on a pool of workers.
+// Every run gets its own RNG seeded from the index of the run, and the results
+// are returned in the order of cfg.Runs, so they don't depend on the number of workers.
+// Every solution is checked for feasibility, in strict mode the first violation is returned as an error.
+// It fails without running anything if the method can't solve the problem.
+//
+// Once ctx is cancelled no further runs are started and the runs in progress stop with their
+// best solution so far, they are marked as interrupted. The runs performed are returned in order
+// together with the error of ctx.
+func Run(ctx context.Context, cfg Config) ([]RunResult, error) {
+	if restricted, ok := cfg.Method.(solver.Restricted); ok {
+		if err := restricted.Supports(cfg.Problem); err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.Method.Name(), err)
+		}
+	}
+	workers := max(1, min(cfg.Workers, len(cfg.Runs)))
+	results := make([]RunResult, len(cfg.Runs))
+	performed := make([]bool, len(cfg.Runs))
+	jobs := make(chan int)
+	abort := make(chan struct{})
+
+	var wg sync.WaitGroup
+	var violation error
+	var once sync.Once
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Keep the worker on one OS thread so its CPU time can be measured
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+
+			for k := range jobs {
+				results[k], performed[k] = runOnce(ctx, cfg, cfg.Runs[k]), true
+				if err := results[k].Violation; err != nil {
+					if cfg.Strict {
+						once.Do(func() {
+							violation = err
+							close(abort)
+						})
+					} else {
+						log.Printf("Warning: %v", err)
+					}
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for k := range cfg.Runs {
+		select {
+		case jobs <- k:
+		case <-abort:
+			break dispatch
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if violation != nil {
+		return nil, violation
+	}
+	if err := ctx.Err(); err != nil {
+		var done []RunResult
+		for k, run := range results {
+			if performed[k] {
+				done = append(done, run)
+			}
+		}
+		return done, err
+	}
+	return results, nil
+}
+
+func runOnce(ctx context.Context, cfg Config, index int) RunResult {
+	run := RunResult{
+		Index:     index,
+		Seed:      solver.DeriveSeed(cfg.Seed, index),
+		StartNode: index % cfg.Problem.NumNodes(),
+	}
+
+	var trace []solver.TracePoint
+	var observe func(solver.TracePoint)
+	if cfg.Trace {
+		observe = func(point solver.TracePoint) { trace = append(trace, point) }
+	}
+
+	cpuStart, cpuOk := solver.ThreadCPUTime()
+	timeIt := time.Now()
+	run.Result = cfg.Method.Solve(ctx, solver.Run{
+		Problem:   cfg.Problem.WithCounters(&run.Counters),
+		StartNode: run.StartNode,
+		Params:    cfg.Params,
+		Budget:    cfg.Budget,
+		Seed:      run.Seed,
+		Rng:       solver.NewRng(run.Seed),
+		Observe:   observe,
+	})
+	elapsed := time.Since(timeIt)
+	run.WallTime = elapsed.Seconds()
+	run.Interrupted = ctx.Err() != nil
+	run.Counters.Iterations = run.Iterations
+	if cfg.Trace {
+		// The result closes the trace, it is the only point of methods that don't report progress
+		run.Trace = append(trace, solver.TracePoint{Elapsed: elapsed, Evaluations: run.Counters.Fitness, Fitness: run.Fitness, BestFitness: run.Fitness})
+	}
+	if cpuEnd, ok := solver.ThreadCPUTime(); ok && cpuOk {
+		run.CPUTime = (cpuEnd - cpuStart).Seconds()
+	}
+
+	if err := utils.ValidateSolution(run.Solution, cfg.Problem.NumNodes(), cfg.Problem.Selection); err != nil {
+		run.Violation = fmt.Errorf("%s returned an infeasible solution in run %d (seed %d): %w",
+			cfg.Method.Name(), index, run.Seed, err)
+	} else {
+		run.Length = cfg.Problem.Length(run.Solution)
+		run.NodeCost = cfg.Problem.NodeCost(run.Solution)
+	}
+
+	return run
+}
@@ -0,0 +1,81 @@
+package experiment
+
+import (
+	"encoding/json"
+	"evolutionary_computation/plot"
+	"evolutionary_computation/utils"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Sink writes results into the logs/<method>/<instance>/ directory tree.
+type Sink struct {
+	Root string
+	// Hook is an optional post-processing command, for example "python scripts/log_results.py".
+	// It is called with the instance file, the written results.json and the method name appended,
+	// followed by any extra arguments given to Write.
+	Hook string
+}
+
+// InstanceName returns the name of an instance file without its directory and extension, e.g. "TSPA".
+func InstanceName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Dir returns the directory the results of a method on an instance are written to.
+// The extra path elements are appended, e.g. for the variants of global convexity.
+func (s Sink) Dir(method, instancePath string, extra ...string) string {
+	return filepath.Join(append([]string{s.Root, method, InstanceName(instancePath)}, extra...)...)
+}
+
+// Write stores the results as results.json in the directory of the method and instance and
+// runs the hook on it. It returns the path of the written file.
+func (s Sink) Write(method, instancePath string, results any, extra ...string) (string, error) {
+	dir := s.Dir(method, instancePath, extra...)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	bytes, err := json.MarshalIndent(results, "", "    ")
+	if err != nil {
+		return "", fmt.Errorf("marshalling results: %w", err)
+	}
+
+	path := filepath.Join(dir, "results.json")
+	if err := os.WriteFile(path, bytes, 0o644); err != nil {
+		return "", err
+	}
+
+	if s.Hook != "" {
+		if err := s.runHook(instancePath, path, method, extra...); err != nil {
+			return path, fmt.Errorf("post-processing hook: %w", err)
+		}
+	}
+	return path, nil
+}
+
+func (s Sink) runHook(instancePath, resultsPath, method string, extra ...string) error {
+	fields := strings.Fields(s.Hook)
+	args := append(fields[1:], instancePath, resultsPath, method)
+	args = append(args, extra...)
+
+	cmd := exec.Command(fields[0], args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// WritePlots renders the best and worst solution of the results as SVG images into dir.
+func WritePlots(dir string, instance *utils.Instance, results Results) error {
+	method := strings.ToUpper(results.Method)
+	if err := plot.TourFile(filepath.Join(dir, "best_solution.svg"), instance, results.BestSolution,
+		fmt.Sprintf("%s: best solution(%d)", method, results.BestFitness)); err != nil {
+		return err
+	}
+	return plot.TourFile(filepath.Join(dir, "worst_solution.svg"), instance, results.WorstSolution,
+		fmt.Sprintf("%s: worst solution(%d)", method, results.WorstFitness))
+}
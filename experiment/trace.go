@@ -0,0 +1,187 @@
+package experiment
+
+import (
+	"encoding/csv"
+	"evolutionary_computation/solver"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// Trace is the progress of one run, as reported by its method, ending with its result.
+type Trace struct {
+	Run    int // index of the run
+	Points []solver.TracePoint
+}
+
+// Anytime summarizes how fast the runs converge, from their traces. Only the runs that reported
+// their progress are summarized: the trace of a method that doesn't, such as a plain local search,
+// holds just its result, which would count as reached from the start of the run.
+type Anytime struct {
+	// Runs are the indices of the summarized runs, empty when no run reported its progress
+	Runs []int `json:"runs"`
+	// Horizon is the end of the longest run in seconds, every curve is measured up to it
+	Horizon float64 `json:"horizon"`
+	// AUC is the area under the best-so-far fitness curve of each run divided by the horizon,
+	// that is its mean best fitness over time. Lower is better, before its first point a run
+	// counts with the fitness of that point and after its end with its final fitness.
+	AUC     []float64     `json:"auc"`
+	MeanAUC float64       `json:"mean_auc"`
+	Target  *TimeToTarget `json:"time_to_target,omitempty"`
+}
+
+// TimeToTarget records when each summarized run first reached a target fitness, in the order
+// of Anytime.Runs.
+type TimeToTarget struct {
+	Target      int       `json:"target"`
+	Times       []float64 `json:"times"`       // in seconds, -1 for runs that never reached the target
+	Evaluations []int     `json:"evaluations"` // fitness evaluations until then, -1 likewise
+	SuccessRate float64   `json:"success_rate"`
+	// ExpectedTime is the time spent by all runs, successful or not, per successful run.
+	// It is infinite, and written as -1, when no run reached the target.
+	ExpectedTime float64 `json:"expected_time"`
+}
+
+// ECDFPoint is a step of the empirical distribution of the times to target:
+// Fraction of all runs reached the target within Time seconds.
+type ECDFPoint struct {
+	Time     float64
+	Fraction float64
+}
+
+// NewAnytime summarizes the traces with more than their closing point, with the times to target
+// when target is not nil.
+func NewAnytime(traces []Trace, target *int) *Anytime {
+	var progress []Trace
+	for _, trace := range traces {
+		if len(trace.Points) > 1 {
+			progress = append(progress, trace)
+		}
+	}
+	traces = progress
+
+	anytime := &Anytime{Runs: make([]int, len(traces)), AUC: make([]float64, len(traces))}
+	for k, trace := range traces {
+		anytime.Runs[k] = trace.Run
+	}
+	for _, trace := range traces {
+		if n := len(trace.Points); n > 0 {
+			anytime.Horizon = math.Max(anytime.Horizon, trace.Points[n-1].Elapsed.Seconds())
+		}
+	}
+	for k, trace := range traces {
+		anytime.AUC[k] = meanBestSoFar(trace.Points, anytime.Horizon)
+		anytime.MeanAUC += anytime.AUC[k] / float64(len(traces))
+	}
+	if target != nil && len(traces) > 0 {
+		anytime.Target = timeToTarget(traces, *target)
+	}
+	return anytime
+}
+
+// meanBestSoFar integrates the best-so-far step curve of the trace over [0, horizon].
+func meanBestSoFar(trace []solver.TracePoint, horizon float64) float64 {
+	if len(trace) == 0 {
+		return 0
+	}
+	if horizon <= 0 {
+		return float64(trace[len(trace)-1].BestFitness)
+	}
+	area := 0.0
+	best := trace[0].BestFitness
+	from := 0.0
+	for _, point := range trace {
+		to := point.Elapsed.Seconds()
+		area += float64(best) * (to - from)
+		best, from = min(best, point.BestFitness), to
+	}
+	area += float64(best) * (horizon - from)
+	return area / horizon
+}
+
+func timeToTarget(traces []Trace, target int) *TimeToTarget {
+	ttt := &TimeToTarget{
+		Target:      target,
+		Times:       make([]float64, len(traces)),
+		Evaluations: make([]int, len(traces)),
+	}
+	successes, total := 0, 0.0
+	for k, trace := range traces {
+		ttt.Times[k], ttt.Evaluations[k] = -1, -1
+		for _, point := range trace.Points {
+			if point.BestFitness <= target {
+				ttt.Times[k], ttt.Evaluations[k] = point.Elapsed.Seconds(), point.Evaluations
+				break
+			}
+		}
+		if ttt.Times[k] >= 0 {
+			successes++
+			total += ttt.Times[k]
+		} else if n := len(trace.Points); n > 0 {
+			total += trace.Points[n-1].Elapsed.Seconds()
+		}
+	}
+	ttt.ExpectedTime = -1
+	if successes > 0 {
+		ttt.SuccessRate = float64(successes) / float64(len(traces))
+		ttt.ExpectedTime = total / float64(successes)
+	}
+	return ttt
+}
+
+// ECDF returns the empirical distribution of the times to target, one step per successful run.
+func (t TimeToTarget) ECDF() []ECDFPoint {
+	var times []float64
+	for _, time := range t.Times {
+		if time >= 0 {
+			times = append(times, time)
+		}
+	}
+	sort.Float64s(times)
+	points := make([]ECDFPoint, len(times))
+	for k, time := range times {
+		points[k] = ECDFPoint{Time: time, Fraction: float64(k+1) / float64(len(t.Times))}
+	}
+	return points
+}
+
+// WriteTraces stores the traces of the runs as traces.csv in dir, one line per point with the
+// index of its run, and the distribution of the times to target as ecdf.csv when there is a target.
+func WriteTraces(dir string, results Results) error {
+	rows := [][]string{{"run", "elapsed", "evaluations", "fitness", "best_fitness"}}
+	for _, trace := range results.Traces {
+		run := strconv.Itoa(trace.Run)
+		for _, point := range trace.Points {
+			rows = append(rows, []string{run, strconv.FormatFloat(point.Elapsed.Seconds(), 'f', 6, 64),
+				strconv.Itoa(point.Evaluations), strconv.Itoa(point.Fitness), strconv.Itoa(point.BestFitness)})
+		}
+	}
+	if err := writeCSV(filepath.Join(dir, "traces.csv"), rows); err != nil {
+		return err
+	}
+
+	if results.Anytime == nil || results.Anytime.Target == nil {
+		return nil
+	}
+	rows = [][]string{{"time", "fraction"}}
+	for _, point := range results.Anytime.Target.ECDF() {
+		rows = append(rows, []string{strconv.FormatFloat(point.Time, 'f', 6, 64), strconv.FormatFloat(point.Fraction, 'f', 4, 64)})
+	}
+	return writeCSV(filepath.Join(dir, "ecdf.csv"), rows)
+}
+
+func writeCSV(filename string, rows [][]string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(file)
+	w.WriteAll(rows)
+	if err := w.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,87 @@
+package experiment
+
+import (
+	"evolutionary_computation/solver"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+// point returns a trace point at the given second with the best-so-far fitness.
+func point(second float64, best int) solver.TracePoint {
+	return solver.TracePoint{Elapsed: time.Duration(second * float64(time.Second)), Fitness: best, BestFitness: best}
+}
+
+func TestNewAnytime(t *testing.T) {
+	target := 50
+	tests := []struct {
+		name    string
+		traces  []Trace
+		runs    []int
+		auc     []float64
+		times   []float64 // to the target, nil when no target is summarized
+		horizon float64
+	}{
+		{
+			name:    "progress",
+			traces:  []Trace{{Run: 0, Points: []solver.TracePoint{point(0, 100), point(1, 50), point(2, 50)}}},
+			runs:    []int{0},
+			auc:     []float64{75},
+			times:   []float64{1},
+			horizon: 2,
+		},
+		{
+			name: "shorter run keeps its final fitness",
+			traces: []Trace{
+				{Run: 0, Points: []solver.TracePoint{point(1, 80), point(4, 60)}},
+				{Run: 1, Points: []solver.TracePoint{point(0, 70), point(2, 40)}},
+			},
+			runs:    []int{0, 1},
+			auc:     []float64{(80*4 + 60*0) / 4.0, (70*2 + 40*2) / 4.0},
+			times:   []float64{-1, 2},
+			horizon: 4,
+		},
+		{
+			name: "results only are left out",
+			traces: []Trace{
+				{Run: 0, Points: []solver.TracePoint{point(3, 40)}},
+				{Run: 1, Points: []solver.TracePoint{point(0, 90), point(1, 60)}},
+				{Run: 2, Points: []solver.TracePoint{point(5, 30)}},
+			},
+			runs:    []int{1},
+			auc:     []float64{90},
+			times:   []float64{-1},
+			horizon: 1,
+		},
+		{
+			name:   "no progress at all",
+			traces: []Trace{{Run: 0, Points: []solver.TracePoint{point(3, 40)}}, {Run: 1, Points: []solver.TracePoint{point(2, 30)}}},
+			runs:   []int{},
+			auc:    []float64{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			anytime := NewAnytime(test.traces, &target)
+			if !slices.Equal(anytime.Runs, test.runs) {
+				t.Fatalf("runs %v, expected %v", anytime.Runs, test.runs)
+			}
+			if anytime.Horizon != test.horizon {
+				t.Fatalf("horizon %g, expected %g", anytime.Horizon, test.horizon)
+			}
+			if !slices.EqualFunc(anytime.AUC, test.auc, func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }) {
+				t.Fatalf("AUC %v, expected %v", anytime.AUC, test.auc)
+			}
+			if test.times == nil {
+				if anytime.Target != nil {
+					t.Fatalf("time to target %+v without runs", anytime.Target)
+				}
+				return
+			}
+			if anytime.Target == nil || !slices.Equal(anytime.Target.Times, test.times) {
+				t.Fatalf("time to target %+v, expected the times %v", anytime.Target, test.times)
+			}
+		})
+	}
+}
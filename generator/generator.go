@@ -0,0 +1,148 @@
+// Package generator creates random instances in the x;y;cost format of the data directory.
+package generator
+
+import (
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// Coordinate distributions.
+var CoordinateKinds = []string{"uniform", "clustered", "grid"}
+
+// Cost distributions. Correlated costs grow with the distance of the node from the centre
+// of the plane, anti-correlated costs fall with it.
+var CostKinds = []string{"uniform", "heavy", "correlated", "anticorrelated"}
+
+// Spec describes a random instance. The defaults match the ranges of TSPA and TSPB.
+type Spec struct {
+	Nodes       int    `json:"nodes"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Coordinates string `json:"coordinates"`
+	Clusters    int    `json:"clusters,omitempty"` // of the clustered coordinates
+	Costs       string `json:"costs"`
+	MinCost     int    `json:"min_cost"`
+	MaxCost     int    `json:"max_cost"`
+	Seed        int64  `json:"seed"`
+}
+
+// DefaultSpec returns the spec of an instance like TSPA with the given seed.
+func DefaultSpec(seed int64) Spec {
+	return Spec{
+		Nodes:       200,
+		Width:       4000,
+		Height:      2000,
+		Coordinates: "uniform",
+		Clusters:    8,
+		Costs:       "uniform",
+		MinCost:     1,
+		MaxCost:     2000,
+		Seed:        seed,
+	}
+}
+
+// Name returns a file name describing the spec, without the seed.
+func (s Spec) Name() string {
+	return fmt.Sprintf("%s_%s_%d", s.Coordinates, s.Costs, s.Nodes)
+}
+
+// Validate returns an error if no instance can be generated from the spec.
+func (s Spec) Validate() error {
+	switch {
+	case s.Nodes < 3:
+		return fmt.Errorf("an instance needs at least 3 nodes, got %d", s.Nodes)
+	case s.Width <= 0 || s.Height <= 0:
+		return fmt.Errorf("invalid plane %dx%d", s.Width, s.Height)
+	case !slices.Contains(CoordinateKinds, s.Coordinates):
+		return fmt.Errorf("unknown coordinates %q, expected one of %v", s.Coordinates, CoordinateKinds)
+	case s.Coordinates == "clustered" && s.Clusters < 1:
+		return fmt.Errorf("clustered coordinates need at least 1 cluster, got %d", s.Clusters)
+	case !slices.Contains(CostKinds, s.Costs):
+		return fmt.Errorf("unknown costs %q, expected one of %v", s.Costs, CostKinds)
+	case s.MinCost > s.MaxCost:
+		return fmt.Errorf("min cost %d is above max cost %d", s.MinCost, s.MaxCost)
+	}
+	return nil
+}
+
+// Generate returns the nodes of a random instance. The same spec always gives the same nodes.
+func Generate(spec Spec) ([]utils.Node, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	rng := solver.NewRng(spec.Seed)
+	nodes := make([]utils.Node, spec.Nodes)
+	w, h := float64(spec.Width), float64(spec.Height)
+
+	switch spec.Coordinates {
+	case "uniform":
+		for i := range nodes {
+			nodes[i].X, nodes[i].Y = math.Floor(rng.Float64()*w), math.Floor(rng.Float64()*h)
+		}
+	case "clustered":
+		// Gaussian clusters around uniform centres, kept inside the plane
+		sigma := 0.05 * math.Min(w, h)
+		centres := make([][2]float64, spec.Clusters)
+		for c := range centres {
+			centres[c] = [2]float64{rng.Float64() * w, rng.Float64() * h}
+		}
+		for i := range nodes {
+			c := centres[rng.Intn(spec.Clusters)]
+			nodes[i].X = clamp(math.Round(c[0]+rng.NormFloat64()*sigma), 0, w-1)
+			nodes[i].Y = clamp(math.Round(c[1]+rng.NormFloat64()*sigma), 0, h-1)
+		}
+	case "grid":
+		// Rows of equally spaced nodes with the aspect ratio of the plane, the last row may be partial
+		cols := int(math.Ceil(math.Sqrt(float64(spec.Nodes) * w / h)))
+		rows := (spec.Nodes + cols - 1) / cols
+		for i := range nodes {
+			nodes[i].X = math.Floor((float64(i%cols) + 0.5) * w / float64(cols))
+			nodes[i].Y = math.Floor((float64(i/cols) + 0.5) * h / float64(rows))
+		}
+	}
+
+	lo, hi := float64(spec.MinCost), float64(spec.MaxCost)
+	maxRadius := math.Hypot(w/2, h/2)
+	for i := range nodes {
+		nodes[i].ID = i
+		var cost float64
+		switch spec.Costs {
+		case "uniform":
+			cost = lo + rng.Float64()*(hi-lo+1)
+		case "heavy":
+			// Pareto with shape 1 truncated to [1, 100] and scaled to [lo, hi]:
+			// mostly cheap nodes and a few expensive ones
+			const ratio = 100
+			x := 1 / (1 - rng.Float64()*(1-1.0/ratio))
+			cost = lo + (x-1)/(ratio-1)*(hi-lo)
+		case "correlated", "anticorrelated":
+			t := math.Hypot(nodes[i].X-w/2, nodes[i].Y-h/2) / maxRadius
+			if spec.Costs == "anticorrelated" {
+				t = 1 - t
+			}
+			// 10% of the range as noise
+			t = clamp(t+0.1*rng.NormFloat64(), 0, 1)
+			cost = lo + t*(hi-lo)
+		}
+		nodes[i].Cost = int(clamp(math.Floor(cost), lo, hi))
+	}
+	return nodes, nil
+}
+
+// Write writes the nodes as semicolon separated x;y;cost lines.
+func Write(w io.Writer, nodes []utils.Node) error {
+	for _, node := range nodes {
+		if _, err := fmt.Fprintf(w, "%d;%d;%d\n", int(node.X), int(node.Y), node.Cost); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func clamp(x, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, x))
+}
@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"encoding/json"
+	"evolutionary_computation/solver"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Suite is a benchmark of instances of every combination of size, coordinates and costs,
+// with several replicates each.
+type Suite struct {
+	Sizes       []int    `json:"sizes"`
+	Coordinates []string `json:"coordinates"`
+	Costs       []string `json:"costs"`
+	Replicates  int      `json:"replicates"`
+	// Base is the spec the combinations are applied to, its seed is the seed of the whole suite
+	Base Spec `json:"base"`
+}
+
+// Manifest lists the instances of a suite with the specs they were generated from.
+type Manifest struct {
+	Suite     Suite           `json:"suite"`
+	Instances []ManifestEntry `json:"instances"`
+}
+
+// ManifestEntry is one instance of a suite, its file is relative to the manifest.
+type ManifestEntry struct {
+	File string `json:"file"`
+	Spec Spec   `json:"spec"`
+}
+
+// Specs returns the specs of all instances of the suite.
+// The seed of each instance is derived from the seed of the suite and the position of the instance.
+func (s Suite) Specs() []Spec {
+	var specs []Spec
+	for _, size := range s.Sizes {
+		for _, coordinates := range s.Coordinates {
+			for _, costs := range s.Costs {
+				for r := 0; r < s.Replicates; r++ {
+					spec := s.Base
+					spec.Nodes, spec.Coordinates, spec.Costs = size, coordinates, costs
+					spec.Seed = solver.DeriveSeed(s.Base.Seed, len(specs))
+					specs = append(specs, spec)
+				}
+			}
+		}
+	}
+	return specs
+}
+
+// WriteSuite generates every instance of the suite into the directory and writes manifest.json
+// next to them. It returns the manifest.
+func WriteSuite(dir string, suite Suite) (Manifest, error) {
+	manifest := Manifest{Suite: suite}
+	specs := suite.Specs()
+	for _, spec := range specs {
+		if err := spec.Validate(); err != nil {
+			return manifest, err
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return manifest, err
+	}
+
+	replicate := make(map[string]int)
+	for _, spec := range specs {
+		name := spec.Name()
+		file := fmt.Sprintf("%s_%d.csv", name, replicate[name])
+		replicate[name]++
+		if err := WriteFile(filepath.Join(dir, file), spec); err != nil {
+			return manifest, err
+		}
+		manifest.Instances = append(manifest.Instances, ManifestEntry{File: file, Spec: spec})
+	}
+
+	bytes, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return manifest, err
+	}
+	return manifest, os.WriteFile(filepath.Join(dir, "manifest.json"), bytes, 0o644)
+}
+
+// WriteFile generates the instance of the spec into a file.
+func WriteFile(filename string, spec Spec) error {
+	nodes, err := Generate(spec)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := Write(file, nodes); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
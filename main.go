@@ -1,64 +1,70 @@
 package main
 
 import (
-	"encoding/json"
-	"evolutionary_computation/methods"
+	"context"
+	"errors"
+	"evolutionary_computation/bound"
+	"evolutionary_computation/experiment"
+	_ "evolutionary_computation/methods"
 	"evolutionary_computation/methods/local_search"
+	"evolutionary_computation/solver"
 	"evolutionary_computation/utils"
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strconv"
-	"time"
+	"strings"
 )
 
 var iterations = 200
 
-type MethodFunc func([][]int, int) []int
-
-var methodsMap = map[string]MethodFunc{
-	"random":                                           methods.RandomSolution,
-	"nearest_neighbour_end_only":                       methods.NearestNeighborEndOnly,
-	"nearest_neighbour_flexible":                       methods.NearestNeighborFlexible,
-	"greedy_cycle":                                     methods.GreedyCycle,
-	"greedy2regret":                                    methods.GreedyTwoRegret,
-	"greedy2regret_weights":                            methods.GreedyRegretWeight,
-	"LS_random_greedy_intranode":                       local_search.RandomGreedyIntraNode,
-	"LS_random_greedy_intraedge":                       local_search.RandomGreedyIntraEdge,
-	"LS_random_steepest_intranode":                     local_search.RandomSteepestIntraNode,
-	"LS_random_steepest_intraedge":                     local_search.RandomSteepestIntraEdge,
-	"LS_nearest_neighbour_flexible_greedy_intranode":   local_search.NearestNeighbourFlexibleGreedyIntraNode,
-	"LS_nearest_neighbour_flexible_greedy_intraedge":   local_search.NearestNeighbourFlexibleGreedyIntraEdge,
-	"LS_nearest_neighbour_flexible_steepest_intranode": local_search.NearestNeighbourFlexibleSteepestIntraNode,
-	"LS_nearest_neighbour_flexible_steepest_intraedge": local_search.NearestNeighbourFlexibleSteepestIntraEdge,
-	"LS_candidates":                                    local_search.LS_Candidates,
-	"LS_delta":                                         local_search.LS_Delta,
-	"LS_multi":                                         local_search.MultiLocalSearch,
-	"LS_iterative":                                     local_search.IterativeLocalSearch,
-	"large_noLS":                                       local_search.LargeNeighbourhood,
-	"large_LS":                                         local_search.LargeNeighbourhoodWithLS,
-	"custom":                                           local_search.CustomMethod,
-	"hybrid":                                           local_search.HybridEA,
-}
+// seed of the whole experiment, the seed of each run is derived from it
+var seed int64
 
-type Results struct {
-	BestSolution   []int     `json:"best_solution"`
-	BestFitness    int       `json:"best_fitness"`
-	WorstSolution  []int     `json:"worst_solution"`
-	WorstFitness   int       `json:"worst_fitness"`
-	AverageFitness float32   `json:"average_fitness"`
-	ExecutionTime  []float64 `json:"execution_time"` // in seconds
-}
+// index of the single run to replay, -1 runs all of them
+var replayRun = -1
+
+// number of runs performed concurrently
+var workers = 1
+
+// abort on the first infeasible solution instead of counting them
+var strict bool
+
+// how many nodes to select, see utils.ParseSelection
+var selectSpec = "half"
+
+// weights of the length and the node costs in the objective
+var weights = utils.UnitWeights
+
+// budget of each run of a metaheuristic, the zero value leaves it to the method
+var budget solver.Budget
+
+// runs of LS_multi measured to calibrate the budget
+var calibrationRuns = 5
+
+// compare the fitness with a lower bound on the optimum
+var reportGap bool
+
+// record the convergence of every run, implied by a target
+var trace bool
+
+// fitness whose time to reach is reported, nil for none
+var target *int
+
+// where results are written and how they are post-processed
+var sink = experiment.Sink{Root: "logs"}
 
 /////////////////////////////////////////////////////////////////////////////////
 // Variables for global convexity
 
 // run with respective command depending on data used !!!
-// go run main.go data/TSPA.csv global_convexity
+// go run . data/TSPA.csv global_convexity
 var PATH_TO_BEST = "logs/NAMEOFFUNCTION/TSPA/results.json"
 
-// go run main.go data/TSPB.csv global_convexity
+// go run . data/TSPB.csv global_convexity
 // var PATH_TO_BEST = "logs/NAMEOFFUNCTION/TSPB/results.json"
 
 // this runs all experiments for given dataset(change if needed)
@@ -67,39 +73,74 @@ var similarities_to = []string{"best", "average"}
 
 // ///////////////////////////////////////////////////////////////////////////////
 func main() {
-	inputFile, methodName := parseArgs()
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			command(os.Args[2:])
+			return
+		}
+	}
+
+	inputFile, methodName, overrides := parseArgs()
+	ctx := interruptible()
 
-	nodes, err := utils.LoadNodes(inputFile)
+	instance, err := utils.LoadInstance(inputFile)
 	if err != nil {
-		log.Fatalf("Error loading nodes from %s: %v", inputFile, err)
+		log.Fatalf("Error loading instance from %s: %v", inputFile, err)
 	}
 
-	costMatrix := utils.CalculateCostMatrix(nodes)
-
-	if methodFunc, ok := methodsMap[methodName]; ok {
-		results := runMethod(methodFunc, costMatrix)
+	selection, err := utils.ParseSelection(selectSpec, len(instance.Nodes))
+	if err != nil {
+		log.Fatalf("Invalid --select: %v", err)
+	}
+	problem, err := utils.NewProblem(instance, selection, weights)
+	if err != nil {
+		log.Fatalf("Invalid problem: %v", err)
+	}
 
-		jsonResults, err := json.Marshal(results)
+	if method, ok := solver.Lookup(methodName); ok {
+		params, err := solver.Resolve(method.Params(), overrides)
 		if err != nil {
-			log.Fatalf("Error marshalling results: %v", err)
+			log.Fatalf("Invalid parameters for %s: %v", methodName, err)
 		}
-
-		tempFile, err := os.CreateTemp("", "results.json")
-		if err != nil {
-			log.Fatalf("Error creating temp file: %v", err)
+		if budget.Calibrate {
+			fmt.Printf("Calibrating the %v time budget on %d runs of %s\n", budget.Kind, calibrationRuns, experiment.CalibrationMethod)
+			if budget, err = experiment.Calibrate(ctx, budget, problem, calibrationRuns, workers, seed); err != nil {
+				log.Fatalf("Calibration failed: %v", err)
+			}
+		}
+		fmt.Printf("Running %s with parameters: %v, selecting %v nodes\n", methodName, params, selection)
+		if !budget.IsZero() {
+			fmt.Printf("Budget of each run: %v\n", budget)
 		}
-		defer tempFile.Close()
 
-		if _, err := tempFile.Write(jsonResults); err != nil {
-			log.Fatalf("Error writing to temp file: %v", err)
+		results := runMethod(ctx, method, params, problem)
+		results.Selection = &selection
+		results.Weights = &weights
+		if !budget.IsZero() {
+			results.Budget = &budget
 		}
+		results.Metadata = experiment.NewMetadata(inputFile, iterations, workers)
 
-		cmd := exec.Command("python", "scripts/log_results.py", inputFile, tempFile.Name(), methodName)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
-			log.Fatalf("Error running python script: %v", err)
+		// A replayed run goes next to the results of the experiment rather than over them
+		var extra []string
+		if replayRun >= 0 {
+			extra = append(extra, fmt.Sprintf("replay_%d", replayRun))
+		}
+		path, err := sink.Write(methodName, inputFile, results, extra...)
+		if err != nil {
+			log.Fatalf("Error writing results: %v", err)
+		}
+		if instance.HasCoordinates {
+			if err := experiment.WritePlots(filepath.Dir(path), instance, results); err != nil {
+				log.Fatalf("Error plotting solutions: %v", err)
+			}
+		}
+		if results.Traces != nil {
+			if err := experiment.WriteTraces(filepath.Dir(path), results); err != nil {
+				log.Fatalf("Error writing traces: %v", err)
+			}
 		}
+		fmt.Printf("Results written to %s\n", path)
 	} else if methodName == "global_convexity" {
 		// Open the JSON file with best solution
 		bestSolution, err := utils.LoadBestSolution(PATH_TO_BEST)
@@ -110,98 +151,189 @@ func main() {
 		for _, similarity_measure := range similarity_measures {
 			for _, similarity_to := range similarities_to {
 				fmt.Printf("Running global convexity with similarity measure: %s, similarity to: %s\n", similarity_measure, similarity_to)
-				similarities, fitnesses := local_search.GlobalConvexityLS(costMatrix, bestSolution, similarity_measure, similarity_to)
+				similarities, fitnesses := local_search.GlobalConvexityLS(ctx, problem, bestSolution, similarity_measure, similarity_to, solver.NewRng(seed))
 
-				//save to json file
 				results := map[string]interface{}{
+					"method":       methodName,
 					"similarities": similarities,
 					"fitnesses":    fitnesses,
+					"seed":         seed,
+					"metadata":     experiment.NewMetadata(inputFile, 1, 1),
 				}
 
-				jsonResults, err := json.Marshal(results)
-				if err != nil {
-					log.Fatalf("Error marshalling results: %v", err)
-				}
-
-				tempFile, err := os.CreateTemp("", "results.json")
+				path, err := sink.Write(methodName, inputFile, results, similarity_measure+"_"+similarity_to)
 				if err != nil {
-					log.Fatalf("Error creating temp file: %v", err)
-				}
-				defer tempFile.Close()
-
-				if _, err := tempFile.Write(jsonResults); err != nil {
-					log.Fatalf("Error writing to temp file: %v", err)
-				}
-
-				cmd := exec.Command("python", "scripts/log_results_glob_conv.py", inputFile, tempFile.Name(), methodName, similarity_measure, similarity_to)
-				cmd.Stdout = os.Stdout
-				cmd.Stderr = os.Stderr
-				if err := cmd.Run(); err != nil {
-					log.Fatalf("Error running python script: %v", err)
+					log.Fatalf("Error writing results: %v", err)
 				}
+				fmt.Printf("Results written to %s\n", path)
 			}
 		}
 	} else {
-		log.Fatalf("Unknown method: %s", methodName)
+		log.Fatalf("Unknown method: %s\nMethods: %s, global_convexity", methodName, strings.Join(solver.Names(), ", "))
 	}
 }
 
-func runMethod(method MethodFunc, costMatrix [][]int) Results {
-	var bestFitness, worstFitness, totalFitness int
-	var bestSolution, worstSolution []int
-	var times []float64
-
-	for i := 0; i < iterations; i++ {
-		startNode := i % len(costMatrix)
+func runMethod(ctx context.Context, method solver.Solver, params solver.Params, problem *utils.Problem) experiment.Results {
+	runs := experiment.Indices(iterations)
+	if replayRun >= 0 {
+		runs = []int{replayRun}
+	}
 
-		timeIt := time.Now()
-		solution := method(costMatrix, startNode)
-		elapsed := time.Since(timeIt).Seconds()
-		times = append(times, elapsed)
-		fitness := utils.Fitness(solution, costMatrix)
+	runResults, err := experiment.Run(ctx, experiment.Config{
+		Method:  method,
+		Params:  params,
+		Problem: problem,
+		Budget:  budget,
+		Seed:    seed,
+		Runs:    runs,
+		Workers: workers,
+		Strict:  strict,
+		Trace:   trace || target != nil,
+	})
+	interrupted := errors.Is(err, context.Canceled)
+	if err != nil && !interrupted {
+		log.Fatalf("Aborting: %v", err)
+	}
+	if len(runResults) == 0 {
+		log.Fatalf("Interrupted before any run was done")
+	}
+	results, err := experiment.Aggregate(method, params, seed, runResults)
+	if err != nil {
+		log.Fatalf("Aborting: %v", err)
+	}
+	results.Interrupted = results.Interrupted || interrupted
+	if results.Interrupted {
+		fmt.Printf("Interrupted: %d of %d runs done, %d of them cut short\n", len(runResults), len(runs), len(results.InterruptedRuns))
+	}
 
-		if i == 0 || fitness < bestFitness {
-			bestFitness = fitness
-			bestSolution = solution
+	fmt.Printf("Best solution (node indices): %v\nBest fitness: %v (run %d), length %d, node cost %d\n",
+		results.BestSolution, results.BestFitness, results.BestRun, results.BestLength, results.BestNodeCost)
+	fmt.Printf("Worst solution (node indices): %v\nWorst fitness: %v (run %d), length %d, node cost %d\n",
+		results.WorstSolution, results.WorstFitness, results.WorstRun, results.WorstLength, results.WorstNodeCost)
+	fmt.Printf("Average fitness: %f, length %.1f, node cost %.1f\n", results.AverageFitness, results.AverageLength, results.AverageNodeCost)
+	work := results.AverageWork()
+	fmt.Printf("Average work per run: %.0f fitness evaluations, %.0f delta evaluations, %.0f moves, %.0f local searches, %.0f iterations\n",
+		work.Fitness, work.Deltas, work.Moves, work.LocalSearches, work.Iterations)
+	if results.ViolationCount > 0 {
+		fmt.Printf("Infeasible solutions: %d of %d runs\n", results.ViolationCount, len(runs))
+	}
+	if reportGap {
+		results.Gap = experiment.NewGap(results, bound.Lagrangian(problem, results.BestFitness))
+		fmt.Printf("Lower bound: %d, gap of the best %.2f%%, average %.2f%%, worst %.2f%%\n",
+			results.Gap.LowerBound.Value, 100*results.Gap.Best, 100*results.Gap.Average, 100*results.Gap.Worst)
+	}
+	if results.Traces != nil {
+		results.Anytime = experiment.NewAnytime(results.Traces, target)
+		if len(results.Anytime.Runs) == 0 {
+			fmt.Printf("No run reported its progress, %s is left out of the convergence summary\n", method.Name())
+		} else {
+			fmt.Printf("Mean best-so-far fitness over %.2fs (area under the convergence curve) of %d runs: %.1f\n",
+				results.Anytime.Horizon, len(results.Anytime.Runs), results.Anytime.MeanAUC)
 		}
-		if i == 0 || fitness > worstFitness {
-			worstFitness = fitness
-			worstSolution = solution
+		if ttt := results.Anytime.Target; ttt != nil {
+			fmt.Printf("Target %d reached in %.0f%% of the runs", ttt.Target, 100*ttt.SuccessRate)
+			if ttt.ExpectedTime >= 0 {
+				fmt.Printf(", expected time %.3fs", ttt.ExpectedTime)
+			}
+			fmt.Println()
 		}
-		totalFitness += fitness
 	}
+	fmt.Printf("Seed: %d (replay a single run with --seed %d --run <index>)\n", seed, seed)
 
-	averageFitness := float32(totalFitness) / float32(iterations)
-
-	fmt.Printf("Best solution (node indices): %v\nBest fitness: %v\n", bestSolution, bestFitness)
-	fmt.Printf("Worst solution (node indices): %v\nWorst fitness: %v\n", worstSolution, worstFitness)
-	fmt.Printf("Average fitness: %f\n", averageFitness)
-
-	return Results{
-		BestSolution:   bestSolution,
-		BestFitness:    bestFitness,
-		WorstSolution:  worstSolution,
-		WorstFitness:   worstFitness,
-		AverageFitness: averageFitness,
-		ExecutionTime:  times,
-	}
+	return results
 }
 
-func parseArgs() (string, string) {
-	if len(os.Args) < 3 {
-		log.Fatalf("Usage: go run main.go <data_file.csv> <method>| optional <num_iterations>\n")
+// parseArgs reads the positional arguments <instance> <method>[:name=value,...] [num_iterations]
+// and the flags, which may be given anywhere on the command line.
+func parseArgs() (string, string, map[string]string) {
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	configFile := flags.String("config", "", "JSON file with parameter values for each method")
+	flags.Int64Var(&seed, "seed", solver.NewSeed(), "seed of the experiment, defaults to the current time")
+	flags.IntVar(&replayRun, "run", -1, "replay only the run with the given index, written to a replay_<index> directory next to the results")
+	flags.StringVar(&selectSpec, "select", selectSpec, `nodes to select: "half", a number, a fraction such as 0.3, or "free[:min-max]" to let the method choose`)
+	flags.IntVar(&weights.Alpha, "alpha", weights.Alpha, "weight of the length of the cycle in the objective")
+	flags.IntVar(&weights.Beta, "beta", weights.Beta, "weight of the node costs in the objective")
+	flags.Func("budget", `budget of each run of a metaheuristic: "wall:30s", "cpu:20s", "evals:5000", "ls:300", or "calibrate[:cpu]" for the mean time of LS_multi`, func(spec string) (err error) {
+		budget, err = solver.ParseBudget(spec)
+		return err
+	})
+	flags.IntVar(&calibrationRuns, "calibration-runs", calibrationRuns, "runs of LS_multi measured to calibrate the budget")
+	flags.BoolVar(&reportGap, "gap", false, "report the gaps of the solutions to a Lagrangian lower bound on the optimum")
+	flags.BoolVar(&trace, "trace", false, "record the best-so-far fitness over time of every run into traces.csv")
+	flags.Func("target", "fitness whose time to reach is reported for every run, implies --trace", func(value string) error {
+		t, err := strconv.Atoi(value)
+		target = &t
+		return err
+	})
+	flags.BoolVar(&strict, "strict", false, "abort on the first infeasible solution instead of counting violations")
+	flags.StringVar(&sink.Root, "logs", sink.Root, "directory the results are written to")
+	flags.StringVar(&sink.Hook, "hook", "", `optional post-processing command run on each results.json, e.g. "python scripts/log_results.py"`)
+	flags.IntVar(&workers, "workers", 1, fmt.Sprintf("number of runs performed concurrently (this machine has %d CPUs)", runtime.NumCPU()))
+	flags.Usage = func() { usage(flags) }
+
+	args := parseInterspersed(flags, os.Args[1:])
+	if len(args) < 2 || len(args) > 3 {
+		usage(flags)
+		os.Exit(2)
 	}
 
-	file := os.Args[1]
-	method := os.Args[2]
+	file := args[0]
+	method, overrides, err := solver.ParseMethod(args[1])
+	if err != nil {
+		log.Fatalf("Couldn't parse method: %v", err)
+	}
 
-	if len(os.Args) == 4 {
-		i, err := strconv.Atoi(os.Args[3])
+	if len(args) == 3 {
+		i, err := strconv.Atoi(args[2])
 		if err != nil {
 			log.Fatalf("Couldn't convert num iterations to int: %v", err)
 		}
 		iterations = i
 	}
 
-	return file, method
+	if *configFile != "" {
+		config, err := solver.LoadConfig(*configFile)
+		if err != nil {
+			log.Fatalf("Error loading config: %v", err)
+		}
+		// Values given on the command line take precedence over the config file
+		for name, value := range config[method] {
+			if _, ok := overrides[name]; !ok {
+				overrides[name] = value
+			}
+		}
+	}
+
+	return file, method, overrides
+}
+
+// parseInterspersed parses the flags and returns the positional arguments,
+// allowing flags to come after them.
+func parseInterspersed(flags *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		flags.Parse(args)
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func usage(flags *flag.FlagSet) {
+	out := flags.Output()
+	fmt.Fprintf(out, "Usage: go run . [flags] <instance> <method>[:name=value,...] [num_iterations]\n")
+	fmt.Fprintf(out, "       go run . <command> [arguments], commands: %s\n\nFlags:\n", strings.Join(commandNames(), ", "))
+	flags.PrintDefaults()
+	fmt.Fprintf(out, "\nMethods:\n")
+	for _, name := range solver.Names() {
+		s, _ := solver.Lookup(name)
+		fmt.Fprintf(out, "  %s\n", name)
+		for _, p := range s.Params() {
+			fmt.Fprintf(out, "      %s %v (default %v): %s\n", p.Name, p.Type, p.Default, p.Usage)
+		}
+	}
+	fmt.Fprintf(out, "  global_convexity\n")
 }
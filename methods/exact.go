@@ -0,0 +1,153 @@
+package methods
+
+import (
+	"context"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+func init() {
+	solver.Register(exactSolver{})
+}
+
+// MaxExactNodes is the largest instance Exact solves, its table has 2^(n-1)*(n-1) entries of
+// 4 bytes, 176 MB for 22 nodes.
+const MaxExactNodes = 22
+
+// exactSolver runs Exact. It is deterministic, so a single run is enough.
+type exactSolver struct{}
+
+func (exactSolver) Name() string           { return "exact" }
+func (exactSolver) Params() []solver.Param { return nil }
+
+func (exactSolver) Supports(problem *utils.Problem) error {
+	return ExactSupports(problem)
+}
+
+func (exactSolver) Solve(ctx context.Context, run solver.Run) solver.Result {
+	solution, _ := Exact(ctx, run.Problem)
+	if solution == nil {
+		// Interrupted before any cycle was complete
+		solution = NearestNeighborFlexible(run.Problem, run.StartNode)
+	}
+	return solver.NewResult(run.Problem, solution)
+}
+
+// ExactSupports returns an error if the problem is too large for Exact.
+func ExactSupports(problem *utils.Problem) error {
+	n := problem.NumNodes()
+	if n > MaxExactNodes {
+		return fmt.Errorf("the exact solver handles at most %d nodes, the instance has %d", MaxExactNodes, n)
+	}
+	// The table holds 32-bit values, the longest path must fit in them
+	largest := 0
+	for a := 0; a < n; a++ {
+		for b := 0; b < n; b++ {
+			largest = max(largest, abs(problem.Distance(a, b)))
+		}
+		largest = max(largest, abs(problem.Cost(a)))
+	}
+	if 2*n*largest >= math.MaxInt32 {
+		return fmt.Errorf("the distances and costs of the instance are too large for the exact solver")
+	}
+	return nil
+}
+
+// Exact returns an optimal solution with Held-Karp dynamic programming over subsets of nodes.
+// Every cycle is rooted at its lowest node s, the table holds for each set of nodes above s and
+// each node j of the set the cheapest path from s through the set ending in j, node costs included.
+// Only sets up to the largest allowed selection are extended, and the paths with an allowed number
+// of nodes are closed back to s. It takes O(2^n n^2) time. When the context is cancelled it returns
+// the best cycle found until then, nil if there is none, with the error of the context.
+func Exact(ctx context.Context, problem *utils.Problem) ([]int, error) {
+	if err := ExactSupports(problem); err != nil {
+		return nil, err
+	}
+	n := problem.NumNodes()
+	selection := problem.Selection
+	const unreachable = math.MaxInt32
+
+	bestFitness := math.MaxInt
+	var bestSolution []int
+	table := make([]int32, (1<<(n-1))*(n-1))
+	step := make([]int32, (n-1)*(n-1))
+
+	for s := 0; n-s >= selection.Min; s++ {
+		// Bit b of a set stands for node s+1+b
+		m := n - 1 - s
+		node := func(b int) int { return s + 1 + b }
+		dp := table[:(1<<m)*m]
+		for i := range dp {
+			dp[i] = unreachable
+		}
+		// step[j*m+k] is the cost of going from node(j) to node(k) and selecting node(k)
+		for j := 0; j < m; j++ {
+			for k := 0; k < m; k++ {
+				step[j*m+k] = int32(problem.Distance(node(j), node(k)) + problem.Cost(node(k)))
+			}
+			dp[(1<<j)*m+j] = int32(problem.Cost(s) + problem.Distance(s, node(j)) + problem.Cost(node(j)))
+		}
+
+		// Every subset of a set comes before it, so when the context is cancelled the paths through
+		// the sets done are final and the best cycle among them can still be followed back
+		bestSet, bestEnd := 0, -1
+		for set := 1; set < 1<<m && (set%4096 != 0 || ctx.Err() == nil); set++ {
+			size := bits.OnesCount(uint(set)) + 1 // nodes of the path, s included
+			for rest := set; rest != 0; rest &= rest - 1 {
+				j := bits.TrailingZeros(uint(rest))
+				value := dp[set*m+j]
+				if value == unreachable {
+					continue
+				}
+				if size >= selection.Min {
+					if fitness := int(value) + problem.Distance(node(j), s); fitness < bestFitness {
+						bestFitness, bestSet, bestEnd = fitness, set, j
+					}
+				}
+				if size >= selection.Max {
+					continue
+				}
+				for k := 0; k < m; k++ {
+					if set&(1<<k) != 0 {
+						continue
+					}
+					next := (set|1<<k)*m + k
+					if v := value + step[j*m+k]; v < dp[next] {
+						dp[next] = v
+					}
+				}
+			}
+		}
+
+		// Follow the path of the best cycle rooted at s back from its last node
+		if bestEnd >= 0 {
+			path := []int{node(bestEnd)}
+			for set, j := bestSet, bestEnd; set != 1<<j; {
+				prev := set &^ (1 << j)
+				for rest := prev; rest != 0; rest &= rest - 1 {
+					i := bits.TrailingZeros(uint(rest))
+					if dp[prev*m+i] != unreachable && dp[prev*m+i]+step[i*m+j] == dp[set*m+j] {
+						set, j = prev, i
+						break
+					}
+				}
+				path = append(path, node(j))
+			}
+			bestSolution = append([]int{s}, path...)
+		}
+		if err := ctx.Err(); err != nil {
+			return bestSolution, err
+		}
+	}
+	return bestSolution, nil
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
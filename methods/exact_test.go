@@ -0,0 +1,97 @@
+package methods
+
+import (
+	"context"
+	"evolutionary_computation/utils"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// newRandomProblem returns a problem on random points with costs from minCost up to 500.
+func newRandomProblem(t *testing.T, seed int64, numNodes, minCost int, selection utils.Selection, weights utils.Weights) *utils.Problem {
+	t.Helper()
+	rng := rand.New(rand.NewSource(seed))
+	nodes := make([]utils.Node, numNodes)
+	for i := range nodes {
+		nodes[i] = utils.Node{ID: i, X: float64(rng.Intn(1000)), Y: float64(rng.Intn(1000)), Cost: minCost + rng.Intn(500-minCost)}
+	}
+	instance := &utils.Instance{Nodes: nodes, Distances: utils.EuclideanDistances(nodes), HasCoordinates: true}
+	problem, err := utils.NewProblem(instance, selection, weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return problem
+}
+
+// bruteForce returns the best fitness over every cycle of an allowed size, trying each order of
+// each set of nodes with the lowest node of the set first.
+func bruteForce(problem *utils.Problem) int {
+	n := problem.NumNodes()
+	best := math.MaxInt
+	var extend func(cycle []int, used []bool)
+	extend = func(cycle []int, used []bool) {
+		if len(cycle) >= problem.Selection.Min {
+			best = min(best, problem.Fitness(cycle))
+		}
+		if len(cycle) == problem.Selection.Max {
+			return
+		}
+		for node := cycle[0] + 1; node < n; node++ {
+			if !used[node] {
+				used[node] = true
+				extend(append(cycle, node), used)
+				used[node] = false
+			}
+		}
+	}
+	for first := 0; first < n; first++ {
+		extend([]int{first}, make([]bool, n))
+	}
+	return best
+}
+
+func TestExact(t *testing.T) {
+	tests := []struct {
+		name      string
+		numNodes  int
+		minCost   int
+		selection utils.Selection
+		weights   utils.Weights
+	}{
+		{"half of 6", 6, 0, utils.HalfSelection(6), utils.UnitWeights},
+		{"half of 8", 8, 0, utils.HalfSelection(8), utils.UnitWeights},
+		{"all of 7", 7, 0, utils.Selection{Min: 7, Max: 7}, utils.UnitWeights},
+		{"smallest cycle", 5, 0, utils.Selection{Min: 3, Max: 3}, utils.UnitWeights},
+		{"free with prizes", 8, -400, utils.Selection{Min: 3, Max: 8}, utils.UnitWeights},
+		{"free within a range", 8, -300, utils.Selection{Min: 4, Max: 6}, utils.UnitWeights},
+		{"weighted", 8, 0, utils.HalfSelection(8), utils.Weights{Alpha: 3, Beta: 1}},
+		{"costs only", 7, -200, utils.Selection{Min: 3, Max: 7}, utils.Weights{Alpha: 0, Beta: 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for seed := int64(0); seed < 5; seed++ {
+				problem := newRandomProblem(t, seed, test.numNodes, test.minCost, test.selection, test.weights)
+				solution, err := Exact(context.Background(), problem)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := utils.ValidateSolution(solution, problem.NumNodes(), problem.Selection); err != nil {
+					t.Fatalf("seed %d: %v", seed, err)
+				}
+				if fitness, optimum := problem.Fitness(solution), bruteForce(problem); fitness != optimum {
+					t.Fatalf("seed %d: %v has fitness %d, the optimum is %d", seed, solution, fitness, optimum)
+				}
+			}
+		})
+	}
+}
+
+func TestExactSupports(t *testing.T) {
+	if err := ExactSupports(newRandomProblem(t, 1, MaxExactNodes, 0, utils.HalfSelection(MaxExactNodes), utils.UnitWeights)); err != nil {
+		t.Fatalf("%d nodes: %v", MaxExactNodes, err)
+	}
+	if err := ExactSupports(newRandomProblem(t, 1, MaxExactNodes+1, 0, utils.HalfSelection(MaxExactNodes+1), utils.UnitWeights)); err == nil {
+		t.Fatalf("%d nodes are accepted", MaxExactNodes+1)
+	}
+}
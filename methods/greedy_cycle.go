@@ -1,29 +1,37 @@
 package methods
 
-import "evolutionary_computation/utils"
+import (
+	"context"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+)
+
+func init() {
+	solver.Register(solver.NewMethod("greedy_cycle", func(ctx context.Context, run solver.Run) []int {
+		return GreedyCycle(run.Problem, run.StartNode)
+	}))
+}
 
 // GreedyCycle function starts by selecting a random vertex as the starting point.
 // It builds a cycle by repeatedly inserting the nearest vertex that minimizes the cycle length increase.
 // The process continues until all vertices are added to form a complete cycle.
-func GreedyCycle(distanceMatrix [][]int, startNode int) []int {
-
-	numNodes, numToSelect, selectedIDs, visited := utils.GetInitialState(distanceMatrix, startNode)
+func GreedyCycle(problem *utils.Problem, startNode int) []int {
+	tour := utils.NewTour(problem.NumNodes(), []int{startNode})
+	selectedIDs := tour.Order()
 
 	// Continue adding the vertices until all are selected
-	for len(selectedIDs) < numToSelect {
+	for tour.Len() < problem.Min {
 		bestNode := -1
 		bestPosition := -1
 		bestIncrease := int(^uint(0) >> 1) // Max int value
 
 		// Find the best unvisited node to insert and the best position in the cycle
-		for i := 0; i < numNodes; i++ {
-			if !visited[i] {
+		for i := 0; i < tour.NumNodes(); i++ {
+			if !tour.IsSelected(i) {
 				for j := 0; j < len(selectedIDs); j++ {
 					// Calculate the increase in cycle length by inserting node i between selectedIDs[j] and selectedIDs[(j+1) % len(selectedIDs)]
 					next := (j + 1) % len(selectedIDs)
-					increase := distanceMatrix[selectedIDs[j]][i] +
-						distanceMatrix[i][selectedIDs[next]] -
-						distanceMatrix[selectedIDs[j]][selectedIDs[next]]
+					increase := problem.InsertionCost(selectedIDs[j], i, selectedIDs[next])
 
 					// Find the minimum increase
 					if increase < bestIncrease {
@@ -36,9 +44,9 @@ func GreedyCycle(distanceMatrix [][]int, startNode int) []int {
 		}
 
 		// Insert the bestNode in the bestPosition found
-		selectedIDs = append(selectedIDs[:bestPosition], append([]int{bestNode}, selectedIDs[bestPosition:]...)...)
-		visited[bestNode] = true
+		tour.Insert(bestPosition, bestNode)
+		selectedIDs = tour.Order()
 	}
 
-	return selectedIDs
+	return problem.Extend(tour.Solution())
 }
@@ -1,62 +1,74 @@
 package methods
 
 import (
+	"context"
+	"evolutionary_computation/solver"
 	"evolutionary_computation/utils"
 	"math"
 	"sort"
 	// "fmt"
 )
 
-func GreedyTwoRegret(distanceMatrix [][]int, startNode int) []int {
-	_, numToSelect, solution, visited := utils.GetInitialState(distanceMatrix, startNode)
+func init() {
+	solver.Register(solver.NewMethod("greedy2regret", func(ctx context.Context, run solver.Run) []int {
+		return GreedyTwoRegret(run.Problem, run.StartNode)
+	}))
+	solver.Register(solver.NewMethod("greedy2regret_weights", func(ctx context.Context, run solver.Run) []int {
+		return GreedyRegretWeight(run.Problem, run.StartNode,
+			float32(run.Params.Float("weight_regret")), float32(run.Params.Float("weight_change")))
+	},
+		solver.Param{Name: "weight_regret", Type: solver.Float, Default: -4.0, Min: -100, Max: 100, Usage: "weight of the 2-regret, < -3 good for TSP_A"},
+		solver.Param{Name: "weight_change", Type: solver.Float, Default: 1.0, Min: -100, Max: 100, Usage: "weight of the insertion cost, > 1 good for TSP_B"},
+	))
+}
+
+func GreedyTwoRegret(problem *utils.Problem, startNode int) []int {
+	tour := utils.NewTour(problem.NumNodes(), []int{startNode})
 
-	for len(solution) < numToSelect {
-		best1, best2 := twoBestCandidates(visited, solution, distanceMatrix)
+	for tour.Len() < problem.Min {
+		solution := tour.Order()
+		best1, best2 := twoBestCandidates(tour, problem)
 
-		bestCost1, secondBest1, insertPos1 := getBestInsertionCost(best1, solution, distanceMatrix)
+		bestCost1, secondBest1, insertPos1 := getBestInsertionCost(best1, solution, problem)
 		regret1 := bestCost1 - secondBest1
-		bestCost2, secondBest2, insertPos2 := getBestInsertionCost(best2, solution, distanceMatrix)
+		bestCost2, secondBest2, insertPos2 := getBestInsertionCost(best2, solution, problem)
 		regret2 := bestCost2 - secondBest2
 
 		if regret1 >= regret2 {
-			solution = utils.InsertAt(solution, insertPos1, best1)
-			visited[best1] = true
+			tour.Insert(insertPos1, best1)
 		} else {
-			solution = utils.InsertAt(solution, insertPos2, best2)
-			visited[best2] = true
+			tour.Insert(insertPos2, best2)
 		}
 	}
 
-	return solution
+	return problem.Extend(tour.Solution())
 }
 
-func GreedyRegretWeight(distanceMatrix [][]int, startNode int) []int {
-	_, numToSelect, solution, visited := utils.GetInitialState(distanceMatrix, startNode)
-	var weightRegret float32 = -4 // < -3 good for TSP_A
-	var weightChange float32 = 1  // >1 good for TSP_B,
+// GreedyRegretWeight inserts the candidate with the best weighted sum of its 2-regret and insertion cost.
+func GreedyRegretWeight(problem *utils.Problem, startNode int, weightRegret, weightChange float32) []int {
+	tour := utils.NewTour(problem.NumNodes(), []int{startNode})
 
-	for len(solution) < numToSelect {
-		best1, best2 := twoBestCandidates(visited, solution, distanceMatrix)
+	for tour.Len() < problem.Min {
+		solution := tour.Order()
+		best1, best2 := twoBestCandidates(tour, problem)
 
-		bestCost1, secondBest1, insertPos1 := getBestInsertionCost(best1, solution, distanceMatrix)
+		bestCost1, secondBest1, insertPos1 := getBestInsertionCost(best1, solution, problem)
 		regret1 := bestCost1 - secondBest1
-		bestCost2, secondBest2, insertPos2 := getBestInsertionCost(best2, solution, distanceMatrix)
+		bestCost2, secondBest2, insertPos2 := getBestInsertionCost(best2, solution, problem)
 		regret2 := bestCost2 - secondBest2
 
 		totalCost1 := weightRegret*float32(regret1) + weightChange*float32(bestCost1)
 		totalCost2 := weightRegret*float32(regret2) + weightChange*float32(bestCost2)
 
 		if totalCost1 <= totalCost2 {
-			solution = utils.InsertAt(solution, insertPos1, best1)
-			visited[best1] = true
+			tour.Insert(insertPos1, best1)
 		} else {
-			solution = utils.InsertAt(solution, insertPos2, best2)
-			visited[best2] = true
+			tour.Insert(insertPos2, best2)
 		}
 
 	}
 
-	return solution
+	return problem.Extend(tour.Solution())
 }
 
 func calculateWeight(regret int, newFitness int, currentFitness int) int {
@@ -65,7 +77,7 @@ func calculateWeight(regret int, newFitness int, currentFitness int) int {
 	return regretWeight*regret + changeWeight*(newFitness-currentFitness)
 }
 
-func twoBestCandidates(visited map[int]bool, solution []int, distanceMatrix [][]int) (int, int) {
+func twoBestCandidates(tour *utils.Tour, problem *utils.Problem) (int, int) {
 	type candidate struct {
 		node   int
 		cost   int
@@ -74,23 +86,26 @@ func twoBestCandidates(visited map[int]bool, solution []int, distanceMatrix [][]
 
 	var candidates []candidate
 
-	// Evaluate all unvisited nodes
-	for i, seen := range visited {
-		if !seen {
-			cost, _, insertPos := getBestInsertionCost(i, solution, distanceMatrix)
+	// Evaluate all unvisited nodes, in order of their IDs so that ties are broken the same way in every run
+	for i := 0; i < tour.NumNodes(); i++ {
+		if !tour.IsSelected(i) {
+			cost, _, insertPos := getBestInsertionCost(i, tour.Order(), problem)
 			candidates = append(candidates, candidate{node: i, cost: cost, insert: insertPos})
 		}
 	}
 
 	// Sort candidates by their insertion cost (ascending order)
-	sort.Slice(candidates, func(a, b int) bool {
+	sort.SliceStable(candidates, func(a, b int) bool {
 		return candidates[a].cost < candidates[b].cost
 	})
+	if len(candidates) == 1 {
+		return candidates[0].node, candidates[0].node // the last node left
+	}
 	return candidates[0].node, candidates[1].node
 }
 
 // Returns the best insertion cost and the position for the given node in the solution
-func getBestInsertionCost(node int, solution []int, distanceMatrix [][]int) (int, int, int) {
+func getBestInsertionCost(node int, solution []int, problem *utils.Problem) (int, int, int) {
 	bestCost := math.MaxInt32
 	secondBestCost := math.MaxInt32
 	bestPos := 0
@@ -100,15 +115,13 @@ func getBestInsertionCost(node int, solution []int, distanceMatrix [][]int) (int
 
 		if j == 0 {
 			// Insert at the beginning
-			cost = distanceMatrix[node][solution[0]]
+			cost = problem.Distance(node, solution[0]) + problem.Cost(node)
 		} else if j == len(solution) {
 			// Insert at the end
-			cost = distanceMatrix[solution[len(solution)-1]][node]
+			cost = problem.Distance(solution[len(solution)-1], node) + problem.Cost(node)
 		} else {
 			// Insert between solution[j-1] and solution[j]
-			cost = distanceMatrix[solution[j-1]][node] +
-				distanceMatrix[node][solution[j]] -
-				distanceMatrix[solution[j-1]][solution[j]]
+			cost = problem.InsertionCost(solution[j-1], node, solution[j])
 		}
 
 		// Update best and second-best costs and track the best position
@@ -0,0 +1,305 @@
+package local_search
+
+import (
+	"evolutionary_computation/utils"
+	"math"
+	"sort"
+)
+
+// CandidateList is a way to choose, for every node, the few nodes worth connecting it to.
+type CandidateList struct {
+	Name  string
+	Usage string
+	// Geometric lists need the positions of the nodes
+	Geometric bool
+	// Build returns at most k candidates of every node, the most promising first
+	Build func(problem *utils.Problem, k int) [][]int
+}
+
+// CandidateLists are the candidate definitions LS_candidates can run with, the first one is the default.
+var CandidateLists = []CandidateList{
+	{Name: "cheapest", Usage: "the nodes cheapest to reach, counting the distance and the cost of the node reached", Build: cheapestCandidates},
+	{Name: "nearest", Usage: "the nearest nodes by distance only", Build: nearestCandidates},
+	{Name: "quadrant", Usage: "the cheapest nodes in each of the four quadrants around the node", Geometric: true, Build: quadrantCandidates},
+	{Name: "alpha", Usage: "the nodes with the lowest alpha-nearness in the minimum 1-tree", Build: alphaCandidates},
+	{Name: "delaunay", Usage: "the cheapest neighbours of the node in the Delaunay triangulation", Geometric: true, Build: delaunayCandidates},
+}
+
+// bestBy returns the at most k nodes other than i with the lowest keys, ties broken by the second key.
+func bestBy(n, i, k int, key func(j int) (int, int)) []int {
+	type keyed struct{ node, first, second int }
+	nodes := make([]keyed, 0, n-1)
+	for j := 0; j < n; j++ {
+		if j != i {
+			first, second := key(j)
+			nodes = append(nodes, keyed{j, first, second})
+		}
+	}
+	sort.SliceStable(nodes, func(a, b int) bool {
+		if nodes[a].first != nodes[b].first {
+			return nodes[a].first < nodes[b].first
+		}
+		return nodes[a].second < nodes[b].second
+	})
+	best := make([]int, 0, k)
+	for _, node := range nodes[:min(k, len(nodes))] {
+		best = append(best, node.node)
+	}
+	return best
+}
+
+// reach is the cost of going from i to j and selecting j.
+func reach(problem *utils.Problem, i, j int) int {
+	return problem.Distance(i, j) + problem.Cost(j)
+}
+
+func cheapestCandidates(problem *utils.Problem, k int) [][]int {
+	candidates := make([][]int, problem.NumNodes())
+	for i := range candidates {
+		candidates[i] = bestBy(problem.NumNodes(), i, k, func(j int) (int, int) {
+			return reach(problem, i, j), 0
+		})
+	}
+	return candidates
+}
+
+func nearestCandidates(problem *utils.Problem, k int) [][]int {
+	candidates := make([][]int, problem.NumNodes())
+	for i := range candidates {
+		candidates[i] = bestBy(problem.NumNodes(), i, k, func(j int) (int, int) {
+			return problem.Distance(i, j), problem.Cost(j)
+		})
+	}
+	return candidates
+}
+
+// quadrantCandidates takes the k/4 cheapest nodes of every quadrant around the node, so that
+// nodes at the edge of a cluster also get candidates in the other clusters, and fills the list
+// up to k with the cheapest of the other nodes.
+func quadrantCandidates(problem *utils.Problem, k int) [][]int {
+	n := problem.NumNodes()
+	candidates := make([][]int, n)
+	for i := range candidates {
+		ranked := bestBy(n, i, n, func(j int) (int, int) {
+			return reach(problem, i, j), 0
+		})
+		var taken [4]int
+		chosen := make(map[int]bool, k)
+		for _, j := range ranked {
+			if q := quadrant(problem.Points[i], problem.Points[j]); taken[q] < max(1, k/4) && len(chosen) < k {
+				taken[q]++
+				chosen[j] = true
+				candidates[i] = append(candidates[i], j)
+			}
+		}
+		for _, j := range ranked {
+			if len(candidates[i]) >= k {
+				break
+			}
+			if !chosen[j] {
+				candidates[i] = append(candidates[i], j)
+			}
+		}
+	}
+	return candidates
+}
+
+// quadrant returns the quadrant around p that q lies in, counterclockwise from the upper right one.
+func quadrant(p, q utils.Point) int {
+	dx, dy := q.X-p.X, q.Y-p.Y
+	switch {
+	case dx >= 0 && dy >= 0:
+		return 0
+	case dx < 0 && dy >= 0:
+		return 1
+	case dx < 0:
+		return 2
+	}
+	return 3
+}
+
+// alphaCandidates ranks the nodes by their alpha-nearness: how much the minimum 1-tree grows when
+// it is forced to contain the edge. Each edge weighs the distance plus half of the costs of its
+// ends, the share of the objective it carries in a cycle, doubled to stay integer. The 1-tree is
+// the minimum spanning tree of all nodes but node 0, joined to node 0 by its two lightest edges.
+func alphaCandidates(problem *utils.Problem, k int) [][]int {
+	n := problem.NumNodes()
+	weight := func(i, j int) int {
+		return 2*problem.Distance(i, j) + problem.Cost(i) + problem.Cost(j)
+	}
+
+	// Prim's algorithm on the nodes but 0
+	adjacent := make([][]int, n)
+	inTree := make([]bool, n)
+	dist, dad := make([]int, n), make([]int, n)
+	for i := range dist {
+		dist[i], dad[i] = math.MaxInt, -1
+	}
+	dist[1] = 0
+	for range n - 1 {
+		u := -1
+		for v := 1; v < n; v++ {
+			if !inTree[v] && (u == -1 || dist[v] < dist[u]) {
+				u = v
+			}
+		}
+		inTree[u] = true
+		if dad[u] != -1 {
+			adjacent[u] = append(adjacent[u], dad[u])
+			adjacent[dad[u]] = append(adjacent[dad[u]], u)
+		}
+		for v := 1; v < n; v++ {
+			if !inTree[v] && weight(u, v) < dist[v] {
+				dist[v], dad[v] = weight(u, v), u
+			}
+		}
+	}
+
+	// The two lightest edges of node 0, a node joined by a heavier one replaces the second
+	second := bestBy(n, 0, 2, func(j int) (int, int) { return weight(0, j), 0 })
+	secondWeight := weight(0, second[len(second)-1])
+	alphaOfZero := func(j int) int {
+		if j == second[0] || j == second[len(second)-1] {
+			return 0
+		}
+		return weight(0, j) - secondWeight
+	}
+
+	candidates := make([][]int, n)
+	candidates[0] = bestBy(n, 0, k, func(j int) (int, int) { return alphaOfZero(j), weight(0, j) })
+	beta := make([]int, n) // heaviest edge on the path of the tree from i
+	visited := make([]bool, n)
+	stack := make([]int, 0, n)
+	for i := 1; i < n; i++ {
+		for v := range visited {
+			visited[v] = false
+		}
+		beta[i], visited[i] = math.MinInt, true
+		stack = append(stack[:0], i)
+		for len(stack) > 0 {
+			u := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, v := range adjacent[u] {
+				if !visited[v] {
+					beta[v], visited[v] = max(beta[u], weight(u, v)), true
+					stack = append(stack, v)
+				}
+			}
+		}
+		candidates[i] = bestBy(n, i, k, func(j int) (int, int) {
+			if j == 0 {
+				return alphaOfZero(i), weight(i, 0)
+			}
+			return weight(i, j) - beta[j], weight(i, j)
+		})
+	}
+	return candidates
+}
+
+// delaunayCandidates takes the cheapest of the neighbours of the node in the Delaunay triangulation
+// of the nodes, which are few but connect neighbouring clusters. Nodes at the same position
+// share their neighbours.
+func delaunayCandidates(problem *utils.Problem, k int) [][]int {
+	neighbours := delaunay(problem.Points)
+	candidates := make([][]int, problem.NumNodes())
+	for i := range candidates {
+		isNeighbour := make(map[int]bool, len(neighbours[i]))
+		for _, j := range neighbours[i] {
+			isNeighbour[j] = true
+		}
+		candidates[i] = bestBy(problem.NumNodes(), i, min(k, len(isNeighbour)), func(j int) (int, int) {
+			if !isNeighbour[j] {
+				return math.MaxInt, 0
+			}
+			return reach(problem, i, j), 0
+		})
+	}
+	return candidates
+}
+
+// delaunay returns the neighbours of every point in the Delaunay triangulation of the points,
+// built by the Bowyer-Watson algorithm in O(n^2).
+func delaunay(points []utils.Point) [][]int {
+	type triangle struct {
+		a, b, c int
+		x, y    float64 // centre of the circumcircle
+		r2      float64 // squared radius of the circumcircle
+	}
+	n := len(points)
+	neighbours := make([][]int, n)
+	if n < 2 {
+		return neighbours
+	}
+
+	// A triangle containing all points, its corners are the last three points
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		minX, minY, maxX, maxY = math.Min(minX, p.X), math.Min(minY, p.Y), math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+	size := math.Max(1, math.Max(maxX-minX, maxY-minY))
+	midX, midY := (minX+maxX)/2, (minY+maxY)/2
+	all := append(append([]utils.Point(nil), points...),
+		utils.Point{X: midX - 20*size, Y: midY - size},
+		utils.Point{X: midX, Y: midY + 20*size},
+		utils.Point{X: midX + 20*size, Y: midY - size})
+
+	newTriangle := func(a, b, c int) triangle {
+		p, q, r := all[a], all[b], all[c]
+		d := 2 * (p.X*(q.Y-r.Y) + q.X*(r.Y-p.Y) + r.X*(p.Y-q.Y))
+		if d == 0 {
+			return triangle{a: a, b: b, c: c, r2: math.Inf(1)} // degenerate, replaced by the next point
+		}
+		p2, q2, r2 := p.X*p.X+p.Y*p.Y, q.X*q.X+q.Y*q.Y, r.X*r.X+r.Y*r.Y
+		x := (p2*(q.Y-r.Y) + q2*(r.Y-p.Y) + r2*(p.Y-q.Y)) / d
+		y := (p2*(r.X-q.X) + q2*(p.X-r.X) + r2*(q.X-p.X)) / d
+		return triangle{a: a, b: b, c: c, x: x, y: y, r2: (p.X-x)*(p.X-x) + (p.Y-y)*(p.Y-y)}
+	}
+
+	triangles := []triangle{newTriangle(n, n+1, n+2)}
+	twin := make(map[utils.Point]int, n)
+	for i, p := range points {
+		if _, ok := twin[p]; ok {
+			continue
+		}
+		twin[p] = i
+
+		// The triangles whose circumcircle contains the point leave a hole, which is filled
+		// with the triangles joining the point to the edges of the hole
+		edges := make(map[[2]int]int)
+		kept := triangles[:0]
+		for _, t := range triangles {
+			if (p.X-t.x)*(p.X-t.x)+(p.Y-t.y)*(p.Y-t.y) < t.r2 {
+				for _, e := range [3][2]int{{t.a, t.b}, {t.b, t.c}, {t.c, t.a}} {
+					edges[[2]int{min(e[0], e[1]), max(e[0], e[1])}]++
+				}
+			} else {
+				kept = append(kept, t)
+			}
+		}
+		triangles = kept
+		for e, count := range edges {
+			if count == 1 {
+				triangles = append(triangles, newTriangle(e[0], e[1], i))
+			}
+		}
+	}
+
+	linked := make(map[[2]int]bool)
+	for _, t := range triangles {
+		for _, e := range [3][2]int{{t.a, t.b}, {t.b, t.c}, {t.c, t.a}} {
+			a, b := min(e[0], e[1]), max(e[0], e[1])
+			if b < n && !linked[[2]int{a, b}] {
+				linked[[2]int{a, b}] = true
+				neighbours[a] = append(neighbours[a], b)
+				neighbours[b] = append(neighbours[b], a)
+			}
+		}
+	}
+	for i, p := range points {
+		if first := twin[p]; first != i {
+			neighbours[i] = append(append(neighbours[i], neighbours[first]...), first)
+			neighbours[first] = append(neighbours[first], i)
+		}
+	}
+	return neighbours
+}
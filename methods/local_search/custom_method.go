@@ -1,44 +1,56 @@
 package local_search
 
 import (
+	"context"
 	"evolutionary_computation/methods"
+	"evolutionary_computation/solver"
 	"evolutionary_computation/utils"
 	"math"
 	"math/rand"
 	"time"
 )
 
+func init() {
+	solver.Register(solver.New("custom", []solver.Param{
+		{Name: "percentage", Type: solver.Float, Default: 0.43, Min: 0, Max: 1, Usage: "fraction of the solution removed by the destroy operator"},
+		{Name: "temperature", Type: solver.Float, Default: 1500.0, Min: 0, Max: 1e9, Usage: "initial temperature of the simulated annealing acceptance"},
+		{Name: "cooling", Type: solver.Float, Default: 0.995, Min: 0, Max: 1, Usage: "factor the temperature is multiplied by after each iteration"},
+		{Name: "tenure", Type: solver.Int, Default: 20, Min: 0, Max: 1000000, Usage: "number of iterations a visited solution stays tabu"},
+	}, CustomMethod))
+}
+
 // A custom Large Neighbourhood Search method that uses more randomization in destroying
 // it utilizes tabu search to avoid revisiting the same solutions and to explore more of the solution space
 // BestSolution is approved with the use of simulated annealing to improve exploration
-func CustomMethod(costMatrix [][]int, startNode int) []int {
+func CustomMethod(ctx context.Context, run solver.Run) solver.Result {
+	problem, startNode := run.Problem, run.StartNode
 	var bestFitness int
 	var bestSolution []int
 	var currentFitness int
 	var currentSolution []int
 
 	// Parameters
-	percentage := 0.43
-	temperature := 1500.0
-	coolingRate := 0.995
-	tabuTenure := 20
+	percentage := run.Params.Float("percentage")
+	temperature := run.Params.Float("temperature")
+	coolingRate := run.Params.Float("cooling")
+	tabuTenure := run.Params.Int("tenure")
 	tabuList := make(map[string]int) // Tabu list as a map of solution hashes to iteration count
 	callCount := 0
 
-	currentSolution = methods.RandomSolution(costMatrix, startNode)
-	currentFitness = utils.Fitness(currentSolution, costMatrix)
+	currentSolution = methods.RandomSolution(problem, startNode, run.Rng)
+	currentFitness = problem.Fitness(currentSolution)
 	bestFitness = currentFitness
 	bestSolution = currentSolution
 
-	startTime := time.Now()
-
-	for time.Since(startTime) < 3*time.Second {
+	meter := run.Start(ctx, 3*time.Second)
+	meter.Observe(currentFitness, bestFitness)
+	for !meter.Exhausted() {
 		// Destroy and repair solution
-		destroyedSolution := DestroySolutionRandom(currentSolution, percentage)
-		repairedSolution := methods.NearestNeighborFlexibleFromSolution(costMatrix, destroyedSolution)
+		destroyedSolution := DestroySolutionRandom(currentSolution, percentage, run.Rng)
+		repairedSolution := methods.NearestNeighborFlexibleToSize(problem, destroyedSolution, repairSize(problem, len(currentSolution), run.Rng))
 
-		newSolution := SteepestIntraEdgeFromSolution(repairedSolution, costMatrix, startNode)
-		newFitness := utils.Fitness(newSolution, costMatrix)
+		newSolution := SteepestIntraEdgeFromSolution(ctx, repairedSolution, problem, startNode, run.Rng)
+		newFitness := problem.Fitness(newSolution)
 
 		// Convert solution to string (or hash) for tabu list
 		solutionKey := utils.SolutionToString(newSolution)
@@ -47,7 +59,7 @@ func CustomMethod(costMatrix [][]int, startNode int) []int {
 		isTabu := tabuList[solutionKey] > callCount
 		aspiration := newFitness < bestFitness // Override tabu if fitness is better than the best
 
-		if (!isTabu || aspiration) && (newFitness < currentFitness || rand.Float64() < math.Exp(-float64(newFitness-currentFitness)/temperature)) {
+		if (!isTabu || aspiration) && (newFitness < currentFitness || run.Rng.Float64() < math.Exp(-float64(newFitness-currentFitness)/temperature)) {
 			currentSolution = newSolution
 			currentFitness = newFitness
 
@@ -61,6 +73,8 @@ func CustomMethod(costMatrix [][]int, startNode int) []int {
 			}
 		}
 
+		meter.Observe(currentFitness, bestFitness)
+
 		// Cool down the temperature
 		temperature *= coolingRate
 		callCount++
@@ -75,36 +89,46 @@ func CustomMethod(costMatrix [][]int, startNode int) []int {
 		}
 	}
 
-	println("Number of calls:", callCount)
-	return bestSolution
+	return solver.Result{Solution: bestSolution, Fitness: bestFitness, Iterations: callCount}
 }
 
-func DestroySolutionRandom(solution []int, percentage float64) []int {
-    // Calculate the total number of nodes to remove
-    numNodesToRemove := int(float64(len(solution)) * percentage)
-    if numNodesToRemove <= 0 || len(solution) <= 1 {
-        return solution // Nothing to remove
-    }
-
-    modifiedSolution := make([]int, len(solution))
-    copy(modifiedSolution, solution)
-
-    // Randomly decide the number of groups (2 to 12)
-    numGroups := rand.Intn(3) + 2 // Generates a random number in [2, 5]
-
-    // Distribute the nodes to remove across groups
-    groupSizes := make([]int, numGroups)
-    for i := 0; i < numNodesToRemove; i++ {
-        groupSizes[rand.Intn(numGroups)]++ // Increment a random group's size
-    }
-
-    // Randomly remove nodes for each group
-    for _, groupSize := range groupSizes {
-        if groupSize > 0 {
-            start := rand.Intn(len(modifiedSolution) - groupSize + 1) // Select random start index
-            modifiedSolution = append(modifiedSolution[:start], modifiedSolution[start+groupSize:]...)
-        }
-    }
-
-    return modifiedSolution
+// repairSize returns the size a solution of the given size is repaired to. With a free selection it is
+// drawn up to twice the size the solution had, so that the size can grow as well as shrink, the
+// local search removing the nodes that do not pay off.
+func repairSize(problem *utils.Problem, size int, rng *rand.Rand) int {
+	if problem.Fixed() {
+		return problem.Min
+	}
+	largest := min(2*size, problem.Max)
+	return problem.Min + rng.Intn(largest-problem.Min+1)
+}
+
+func DestroySolutionRandom(solution []int, percentage float64, rng *rand.Rand) []int {
+	// Calculate the total number of nodes to remove
+	numNodesToRemove := int(float64(len(solution)) * percentage)
+	if numNodesToRemove <= 0 || len(solution) <= 1 {
+		return solution // Nothing to remove
+	}
+
+	modifiedSolution := make([]int, len(solution))
+	copy(modifiedSolution, solution)
+
+	// Randomly decide the number of groups (2 to 12)
+	numGroups := rng.Intn(3) + 2 // Generates a random number in [2, 5]
+
+	// Distribute the nodes to remove across groups
+	groupSizes := make([]int, numGroups)
+	for i := 0; i < numNodesToRemove; i++ {
+		groupSizes[rng.Intn(numGroups)]++ // Increment a random group's size
+	}
+
+	// Randomly remove nodes for each group
+	for _, groupSize := range groupSizes {
+		if groupSize > 0 {
+			start := rng.Intn(len(modifiedSolution) - groupSize + 1) // Select random start index
+			modifiedSolution = append(modifiedSolution[:start], modifiedSolution[start+groupSize:]...)
+		}
+	}
+
+	return modifiedSolution
 }
@@ -0,0 +1,179 @@
+package local_search
+
+import (
+	"context"
+	"evolutionary_computation/methods"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"math/rand"
+)
+
+func init() {
+	solver.Register(solver.NewMethod("LS_random_dontlook_intranode", func(ctx context.Context, run solver.Run) []int {
+		return RandomDontLook(ctx, run.Problem, run.StartNode, "NodeExchange", run.Rng)
+	}))
+	solver.Register(solver.NewMethod("LS_random_dontlook_intraedge", func(ctx context.Context, run solver.Run) []int {
+		return RandomDontLook(ctx, run.Problem, run.StartNode, "EdgeExchange", run.Rng)
+	}))
+}
+
+// RandomDontLook is the greedy local search of RandomGreedyIntraNode and RandomGreedyIntraEdge
+// driven by don't-look bits: only the moves touching an active node are scanned, and a node is
+// activated again when one of its edges changes. Exchanges and insertions of an unselected node
+// are scanned from the selected nodes they touch, so the local optimum it stops in may still
+// have improving moves the bits skipped.
+func RandomDontLook(ctx context.Context, problem *utils.Problem, startNode int, intraMoveType string, rng *rand.Rand) []int {
+	problem.Counters().LocalSearch()
+	// Run random function to get the initial solution
+	tour := utils.NewTour(problem.NumNodes(), methods.RandomSolution(problem, startNode, rng))
+	dl := &dontLook{
+		tour:          tour,
+		problem:       problem,
+		intraMoveType: intraMoveType,
+		rng:           rng,
+		active:        make([]bool, problem.NumNodes()),
+	}
+	for _, i := range rng.Perm(tour.Len()) {
+		dl.activate(tour.At(i))
+	}
+
+	for len(dl.queue) > 0 && ctx.Err() == nil {
+		node := dl.queue[0]
+		dl.queue = dl.queue[1:]
+		dl.active[node] = false
+		dl.improve(node)
+	}
+	return tour.Solution()
+}
+
+// dontLook keeps the nodes whose don't-look bit is off, in the order they are scanned.
+type dontLook struct {
+	tour          *utils.Tour
+	problem       *utils.Problem
+	intraMoveType string
+	rng           *rand.Rand
+	queue         []int
+	active        []bool
+	order         []int // of the moves scanned, reused by every scan
+}
+
+func (dl *dontLook) activate(node int) {
+	if dl.tour.IsSelected(node) && !dl.active[node] {
+		dl.active[node] = true
+		dl.queue = append(dl.queue, node)
+	}
+}
+
+// improve applies the first improving move touching the selected node, scanning the kinds of moves
+// from a random one and the moves of each kind in a random order, and activates the nodes whose edges
+// it changed, the node among them.
+func (dl *dontLook) improve(node int) {
+	if !dl.tour.IsSelected(node) {
+		return
+	}
+	kinds := [4]func(int) (Move, bool){dl.intraMove, dl.exchangeMove, dl.insertMove, dl.removeMove}
+	first := dl.rng.Intn(len(kinds))
+	for k := range kinds {
+		if move, ok := kinds[(first+k)%len(kinds)](node); ok {
+			touched := dl.touched(move)
+			applyMove(dl.tour, move, dl.problem)
+			for _, t := range touched {
+				dl.activate(t)
+			}
+			return
+		}
+	}
+}
+
+// scan returns the first improving move of the given number, in a random order drawn by
+// a Fisher-Yates shuffle as the moves are scanned. The move function returns false for the
+// ones that do not exist.
+func (dl *dontLook) scan(count int, move func(k int) (Move, bool)) (Move, bool) {
+	if cap(dl.order) < count {
+		dl.order = make([]int, count)
+	}
+	order := dl.order[:count]
+	for k := range order {
+		order[k] = k
+	}
+	for k := range order {
+		r := k + dl.rng.Intn(count-k)
+		order[k], order[r] = order[r], order[k]
+		if m, ok := move(order[k]); ok && delta(dl.tour, m, dl.problem) < 0 {
+			return m, true
+		}
+	}
+	return Move{}, false
+}
+
+// intraMove exchanges the node with another selected node, or one of its two edges with
+// another edge of the solution.
+func (dl *dontLook) intraMove(node int) (Move, bool) {
+	n, p := dl.tour.Len(), dl.tour.Pos(node)
+	if n < 4 {
+		return Move{}, false // all orders of up to 3 nodes are the same cycle
+	}
+	switch dl.intraMoveType {
+	case "NodeExchange":
+		return dl.scan(n, func(q int) (Move, bool) {
+			return Move{moveType: "twoNodesExchange", i: min(p, q), j: max(p, q)}, q != p
+		})
+	case "EdgeExchange":
+		// The edges starting at p and at p-1, each with the edges sharing none of its nodes
+		return dl.scan(2*n, func(k int) (Move, bool) {
+			i, q := (p-k%2+n)%n, k/2
+			if q == i || (q+1)%n == i || (i+1)%n == q {
+				return Move{}, false
+			}
+			return Move{moveType: "twoEdgesExchange", i: min(i, q), j: max(i, q)}, true
+		})
+	}
+	return Move{}, false
+}
+
+// exchangeMove replaces the node with an unselected node.
+func (dl *dontLook) exchangeMove(node int) (Move, bool) {
+	p, unselected := dl.tour.Pos(node), dl.tour.Unselected()
+	return dl.scan(len(unselected), func(k int) (Move, bool) {
+		return Move{moveType: "interRouteExchange", i: p, j: unselected[k]}, true
+	})
+}
+
+// insertMove inserts an unselected node into one of the two edges of the node.
+func (dl *dontLook) insertMove(node int) (Move, bool) {
+	if dl.tour.Len() >= dl.problem.Selection.Max {
+		return Move{}, false
+	}
+	n, p, unselected := dl.tour.Len(), dl.tour.Pos(node), dl.tour.Unselected()
+	return dl.scan(2*len(unselected), func(k int) (Move, bool) {
+		return Move{moveType: "insertNode", i: (p - k%2 + n) % n, j: unselected[k/2]}, true
+	})
+}
+
+// removeMove removes the node.
+func (dl *dontLook) removeMove(node int) (Move, bool) {
+	if dl.tour.Len() <= dl.problem.Selection.Min {
+		return Move{}, false
+	}
+	return dl.scan(1, func(int) (Move, bool) {
+		return Move{moveType: "removeNode", i: dl.tour.Pos(node), j: -1}, true
+	})
+}
+
+// touched returns the nodes whose edges the move changes, before it is applied.
+func (dl *dontLook) touched(move Move) []int {
+	t, i, j := dl.tour, move.i, move.j
+	switch move.moveType {
+	case "twoNodesExchange":
+		return []int{t.At(i - 1), t.At(i), t.At(i + 1), t.At(j - 1), t.At(j), t.At(j + 1)}
+	case "twoEdgesExchange":
+		return []int{t.At(i), t.At(i + 1), t.At(j), t.At(j + 1)}
+	case "interRouteExchange":
+		return []int{t.At(i - 1), j, t.At(i + 1)}
+	case "insertNode":
+		return []int{t.At(i), j, t.At(i + 1)}
+	case "removeNode":
+		return []int{t.At(i - 1), t.At(i + 1)}
+	}
+	return nil
+}
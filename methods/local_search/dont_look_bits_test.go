@@ -0,0 +1,52 @@
+package local_search
+
+import (
+	"context"
+	"evolutionary_computation/utils"
+	"math/rand"
+	"testing"
+)
+
+func TestRandomDontLook(t *testing.T) {
+	tests := []struct {
+		name          string
+		intraMoveType string
+		minCost       int
+		selection     utils.Selection
+	}{
+		{"node exchange", "NodeExchange", 0, utils.HalfSelection(50)},
+		{"edge exchange", "EdgeExchange", 0, utils.HalfSelection(50)},
+		{"node exchange, free", "NodeExchange", -400, utils.Selection{Min: 3, Max: 50}},
+		{"edge exchange, free", "EdgeExchange", -400, utils.Selection{Min: 3, Max: 50}},
+		{"edge exchange, free within a range", "EdgeExchange", -400, utils.Selection{Min: 10, Max: 20}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problem := newTestProblem(t, 7, 50, test.minCost, test.selection, utils.UnitWeights)
+			for seed := int64(0); seed < 10; seed++ {
+				rng := rand.New(rand.NewSource(seed))
+				solution := RandomDontLook(context.Background(), problem, int(seed), test.intraMoveType, rng)
+				if err := utils.ValidateSolution(solution, problem.NumNodes(), problem.Selection); err != nil {
+					t.Fatalf("seed %d: %v", seed, err)
+				}
+
+				// It stops when all bits are set. The exchanges within the cycle only depend on the
+				// edges of the nodes, which activate them when they change, so none of them improves
+				// the solution. Exchanges, insertions and removals may have become possible since the
+				// node was scanned, through nodes unselected or the size changed elsewhere.
+				dl := &dontLook{
+					tour:          utils.NewTour(problem.NumNodes(), solution),
+					problem:       problem,
+					intraMoveType: test.intraMoveType,
+					rng:           rng,
+					active:        make([]bool, problem.NumNodes()),
+				}
+				for _, node := range solution {
+					if move, ok := dl.intraMove(node); ok {
+						t.Fatalf("seed %d: %+v of node %d improves %v by %d", seed, move, node, solution, delta(dl.tour, move, problem))
+					}
+				}
+			}
+		})
+	}
+}
@@ -1,20 +1,22 @@
 package local_search
 
 import (
+	"context"
 	"evolutionary_computation/utils"
+	"math/rand"
 )
 
-func GlobalConvexityLS(costMatrix [][]int, bestsolution []int, similarity_measure string, similarity_to string) ([]float64, []int) {
+func GlobalConvexityLS(ctx context.Context, problem *utils.Problem, bestsolution []int, similarity_measure string, similarity_to string, rng *rand.Rand) ([]float64, []int) {
 	iterations := 1000
 	localOptima := make([][]int, 0, iterations)
 	fitnesses := make([]int, 0, iterations)
 	similarities := make([]float64, 0, iterations)
 
 	// Generate 1000 random solutions and optimize them using greedy local search
-	for i := 0; i < iterations; i++ {
-		startNode := i % len(costMatrix)
-		solution := RandomGreedyIntraEdge(costMatrix, startNode)
-		fitness := utils.Fitness(solution, costMatrix)
+	for i := 0; i < iterations && ctx.Err() == nil; i++ {
+		startNode := i % problem.NumNodes()
+		solution := RandomGreedyIntraEdge(ctx, problem, startNode, rng)
+		fitness := problem.Fitness(solution)
 
 		localOptima = append(localOptima, solution)
 		fitnesses = append(fitnesses, fitness)
@@ -1,36 +1,56 @@
 package local_search
 
 import (
+	"context"
 	"evolutionary_computation/methods"
+	"evolutionary_computation/solver"
 	"evolutionary_computation/utils"
 	"math/rand"
 	"time"
 )
 
+func init() {
+	solver.Register(solver.New("hybrid", []solver.Param{
+		{Name: "elite_size", Type: solver.Int, Default: 20, Min: 2, Max: 10000, Usage: "number of solutions in the elite population"},
+		{Name: "generations", Type: solver.Int, Default: 200, Min: 1, Max: 1000000, Usage: "generations between updates of the best solution"},
+	}, HybridEA))
+}
+
 // HybridEA implements the hybrid evolutionary algorithm
-func HybridEA(costMatrix [][]int, pointless_value int) []int {
+func HybridEA(ctx context.Context, run solver.Run) solver.Result {
+	problem := run.Problem
 	var bestFitness int
 	var bestSolution []int
+	var generations int
 
-	EliteSize := 20
-	MaxGenerations := 200
+	EliteSize := run.Params.Int("elite_size")
+	MaxGenerations := run.Params.Int("generations")
 
-	startTime := time.Now()
+	meter := run.Start(ctx, 24*time.Second)
 
-	// Initialize elite population
-	elitePopulation := initializePopulation(costMatrix, EliteSize)
+	// Initialize elite population, it is always completed whatever the budget
+	elitePopulation := initializePopulation(ctx, problem, EliteSize, run.Rng)
+	bestSeen := elitePopulation[0].Fitness // the elite never loses its best solution
+	for _, solution := range elitePopulation {
+		bestSeen = min(bestSeen, solution.Fitness)
+		meter.Observe(solution.Fitness, bestSeen)
+	}
 
-	for time.Since(startTime) < 24*time.Second {
-		for gen := 0; gen < MaxGenerations; gen++ {
+	// The best solution is taken from the population at least once, even on a spent budget
+	for done := false; !done; done = meter.Exhausted() {
+		for gen := 0; gen < MaxGenerations && !meter.Exhausted(); gen++ {
 			// Select parents
-			parent1, parent2 := selectParents(elitePopulation)
+			parent1, parent2 := selectParents(elitePopulation, run.Rng)
 
 			// Apply recombination
-			offspring := recombine(parent1.Path, parent2.Path, costMatrix)
+			offspring := recombine(parent1.Path, parent2.Path, problem, run.Rng)
 			// Perform local search
-			offspring.Path = NearestNeighbourFlexibleSteepestIntraEdgeFromSolution(costMatrix, offspring.Path)
+			offspring.Path = NearestNeighbourFlexibleSteepestIntraEdgeFromSolution(ctx, problem, offspring.Path, run.Rng)
 
-			offspring.Fitness = utils.Fitness(offspring.Path, costMatrix)
+			offspring.Fitness = problem.Fitness(offspring.Path)
+			generations++
+			bestSeen = min(bestSeen, offspring.Fitness)
+			meter.Observe(offspring.Fitness, bestSeen)
 
 			// Check diversity and update elite population
 			if !isDuplicate(elitePopulation, offspring) {
@@ -46,7 +66,7 @@ func HybridEA(costMatrix [][]int, pointless_value int) []int {
 		}
 	}
 
-	return bestSolution
+	return solver.Result{Solution: bestSolution, Fitness: bestFitness, Iterations: generations}
 }
 
 type HybridSolution struct {
@@ -54,42 +74,43 @@ type HybridSolution struct {
 	Fitness int
 }
 
-func initializePopulation(costMatrix [][]int, size int) []HybridSolution {
+func initializePopulation(ctx context.Context, problem *utils.Problem, size int, rng *rand.Rand) []HybridSolution {
 	population := make([]HybridSolution, size)
 	for i := 0; i < size; i++ {
-		path := RandomSteepestIntraEdge(costMatrix, rand.Intn(len(costMatrix)))
-		fitness := utils.Fitness(path, costMatrix)
+		path := RandomSteepestIntraEdge(ctx, problem, rng.Intn(problem.NumNodes()), rng)
+		fitness := problem.Fitness(path)
 		population[i] = HybridSolution{Path: path, Fitness: fitness}
 	}
 	return population
 }
 
-func selectParents(population []HybridSolution) (HybridSolution, HybridSolution) {
-	parent1 := population[rand.Intn(len(population))]
-	parent2 := population[rand.Intn(len(population))]
+func selectParents(population []HybridSolution, rng *rand.Rand) (HybridSolution, HybridSolution) {
+	parent1 := population[rng.Intn(len(population))]
+	parent2 := population[rng.Intn(len(population))]
 
-	// Ensure parents are different
-	for parent1.Fitness == parent2.Fitness {
-		parent2 = population[rand.Intn(len(population))]
+	// Ensure parents are different, as long as the population isn't all the same
+	for tries := 0; parent1.Fitness == parent2.Fitness && tries < len(population); tries++ {
+		parent2 = population[rng.Intn(len(population))]
 	}
 
 	return parent1, parent2
 }
 
-func recombine(parent1, parent2 []int, costMatrix [][]int) HybridSolution {
-	if rand.Float64() < 0.6 {
-		return recombineOperator1(parent1, parent2)
+func recombine(parent1, parent2 []int, problem *utils.Problem, rng *rand.Rand) HybridSolution {
+	if rng.Float64() < 0.6 {
+		return recombineOperator1(parent1, parent2, problem, rng)
 	}
-	return recombineOperator2(parent1, parent2, costMatrix)
+	return recombineOperator2(parent1, parent2, problem)
 }
 
-func recombineOperator1(parent1, parent2 []int) HybridSolution {
+func recombineOperator1(parent1, parent2 []int, problem *utils.Problem, rng *rand.Rand) HybridSolution {
 	child := make([]int, len(parent1))
-	inChild := make(map[int]bool)
+	inChild := make([]bool, problem.NumNodes())
+	second := utils.NewTour(problem.NumNodes(), parent2)
 
 	// Add common nodes
 	for i := range child {
-		if utils.Contains(parent2, parent1[i]) {
+		if second.IsSelected(parent1[i]) {
 			child[i] = parent1[i]
 			inChild[parent1[i]] = true
 		} else {
@@ -102,9 +123,9 @@ func recombineOperator1(parent1, parent2 []int) HybridSolution {
 		if child[i] == -1 {
 			for {
 				//add random node that is not in solution from random parent solution
-				node := parent1[rand.Intn(len(parent2))]
-				if rand.Float64() < 0.5 {
-					node = parent2[rand.Intn(len(parent2))]
+				node := parent1[rng.Intn(len(parent1))]
+				if rng.Float64() < 0.5 {
+					node = parent2[rng.Intn(len(parent2))]
 				}
 
 				if !inChild[node] {
@@ -119,16 +140,17 @@ func recombineOperator1(parent1, parent2 []int) HybridSolution {
 	return HybridSolution{Path: child}
 }
 
-func recombineOperator2(parent1, parent2 []int, costMatrix [][]int) HybridSolution {
+func recombineOperator2(parent1, parent2 []int, problem *utils.Problem) HybridSolution {
 	commonNodes := []int{}
+	second := utils.NewTour(problem.NumNodes(), parent2)
 	for _, node := range parent1 {
-		if utils.Contains(parent2, node) {
+		if second.IsSelected(node) {
 			commonNodes = append(commonNodes, node)
 		}
 	}
 
 	// Perform nearest neighbor repair starting with the common nodes
-	repairedChild := methods.NearestNeighborFlexibleFromSolution(costMatrix, commonNodes)
+	repairedChild := methods.NearestNeighborFlexibleFromSolution(problem, commonNodes)
 
 	return HybridSolution{Path: repairedChild}
 }
@@ -1,110 +1,117 @@
 package local_search
 
 import (
+	"context"
 	"evolutionary_computation/methods"
-	"evolutionary_computation/utils"
+	"evolutionary_computation/solver"
 	"math/rand"
 	"time"
 )
 
+var largeNeighbourhoodParams = []solver.Param{
+	{Name: "percentage", Type: solver.Float, Default: 0.2, Min: 0, Max: 1, Usage: "fraction of the solution removed by the destroy operator"},
+}
+
+func init() {
+	solver.Register(solver.New("large_noLS", largeNeighbourhoodParams, LargeNeighbourhood))
+	solver.Register(solver.New("large_LS", largeNeighbourhoodParams, LargeNeighbourhoodWithLS))
+}
 
-func LargeNeighbourhoodWithLS(costMatrix [][]int, pointless_value int) []int {
+// LargeNeighbourhoodWithLS destroys and repairs the best solution found so far and improves the result with local search.
+func LargeNeighbourhoodWithLS(ctx context.Context, run solver.Run) solver.Result {
+	problem := run.Problem
 	var bestFitness int
 	var bestSolution []int
 	var callCount int
 	var solution []int
 
-	percentage := 0.2
-
-	startTime := time.Now()
+	percentage := run.Params.Float("percentage")
 
-	//TODO: change the time to average from MultiLocalSearch
-	for time.Since(startTime) < 24*time.Second {
-		startNode := callCount % len(costMatrix)
+	meter := run.Start(ctx, 24*time.Second)
+	for callCount == 0 || !meter.Exhausted() { // at least one solution, even on a spent budget
+		startNode := callCount % problem.NumNodes()
 
 		if callCount == 0 {
-			solution = RandomSteepestIntraEdge(costMatrix, startNode)
+			solution = RandomSteepestIntraEdge(ctx, problem, startNode, run.Rng)
 		} else {
 			solution = bestSolution // Always use the best solution to perform operations
 		}
 		callCount++
 
-		destroyedSolution := DestroySolution(solution, percentage)
-		repairedSolution := methods.NearestNeighborFlexibleFromSolution(costMatrix, destroyedSolution)
-		solution = SteepestIntraEdgeFromSolution(repairedSolution, costMatrix, startNode)
+		destroyedSolution := DestroySolution(solution, percentage, run.Rng)
+		repairedSolution := methods.NearestNeighborFlexibleFromSolution(problem, destroyedSolution)
+		solution = SteepestIntraEdgeFromSolution(ctx, repairedSolution, problem, startNode, run.Rng)
 
-		fitness := utils.Fitness(solution, costMatrix)
+		fitness := problem.Fitness(solution)
 		if callCount == 1 || fitness < bestFitness {
 			bestFitness = fitness
 			bestSolution = solution
 		}
+		meter.Observe(fitness, bestFitness)
 	}
-	// TODO: add callCount to results dict
-	println("Number of calls:", callCount)
-	return bestSolution
+	return solver.Result{Solution: bestSolution, Fitness: bestFitness, Iterations: callCount}
 }
 
-
-func LargeNeighbourhood(costMatrix [][]int, pointless_value int) []int {
+// LargeNeighbourhood destroys and repairs the best solution found so far without local search.
+func LargeNeighbourhood(ctx context.Context, run solver.Run) solver.Result {
+	problem := run.Problem
 	var bestFitness int
 	var bestSolution []int
 	var callCount int
 	var solution []int
 
-	percentage := 0.2
-
-	startTime := time.Now()
+	percentage := run.Params.Float("percentage")
 
-	//TODO: change the time to average from MultiLocalSearch
-	for time.Since(startTime) < 24*time.Second {
-		startNode := callCount % len(costMatrix)
+	meter := run.Start(ctx, 24*time.Second)
+	for callCount == 0 || !meter.Exhausted() { // at least one solution, even on a spent budget
+		startNode := callCount % problem.NumNodes()
 
 		if callCount == 0 {
-			solution = RandomSteepestIntraEdge(costMatrix, startNode)
+			solution = RandomSteepestIntraEdge(ctx, problem, startNode, run.Rng)
 		} else {
 			solution = bestSolution // Always use the best solution to perform operations
 		}
 		callCount++
 
-		destroyedSolution := DestroySolution(solution, percentage)
+		destroyedSolution := DestroySolution(solution, percentage, run.Rng)
 		//NOTE: Culprit number 2 if things break
-		solution := methods.NearestNeighborFlexibleFromSolution(costMatrix, destroyedSolution)
+		solution := methods.NearestNeighborFlexibleFromSolution(problem, destroyedSolution)
+		problem.Counters().LocalSearch() // the repair takes the place of the local search
 
-		fitness := utils.Fitness(solution, costMatrix)
+		fitness := problem.Fitness(solution)
 		if callCount == 1 || fitness < bestFitness {
 			bestFitness = fitness
 			bestSolution = solution
 		}
+		meter.Observe(fitness, bestFitness)
 	}
-	// TODO: add callCount to results dict
-	println("Number of calls:", callCount)
-	return bestSolution
+	return solver.Result{Solution: bestSolution, Fitness: bestFitness, Iterations: callCount}
 }
 
-func DestroySolution(solution []int, percentage float64) []int {
-    numNodesToRemove := int(float64(len(solution)) * percentage)
-    if numNodesToRemove == 0 {
-        return solution // Nothing to remove
-    }
-
-    // Split into 3 groups (as evenly as possible)
-    groupSizes := []int{
-        numNodesToRemove / 3,
-        numNodesToRemove / 3,
-        numNodesToRemove - 2*(numNodesToRemove/3), // Remaining nodes go to the last group
-    }
-
-    modifiedSolution := make([]int, len(solution))
-    copy(modifiedSolution, solution)
-
-    for _, groupSize := range groupSizes {
-        if groupSize > 0 {
-            // Randomly select a starting index and create a subpath of groupSize
-            start := rand.Intn(len(modifiedSolution) - groupSize + 1)
+func DestroySolution(solution []int, percentage float64, rng *rand.Rand) []int {
+	numNodesToRemove := int(float64(len(solution)) * percentage)
+	if numNodesToRemove == 0 {
+		return solution // Nothing to remove
+	}
+
+	// Split into 3 groups (as evenly as possible)
+	groupSizes := []int{
+		numNodesToRemove / 3,
+		numNodesToRemove / 3,
+		numNodesToRemove - 2*(numNodesToRemove/3), // Remaining nodes go to the last group
+	}
+
+	modifiedSolution := make([]int, len(solution))
+	copy(modifiedSolution, solution)
+
+	for _, groupSize := range groupSizes {
+		if groupSize > 0 {
+			// Randomly select a starting index and create a subpath of groupSize
+			start := rng.Intn(len(modifiedSolution) - groupSize + 1)
 			// NOTE: If things break, this is the most likely culprit
-            modifiedSolution = append(modifiedSolution[:start], modifiedSolution[start+groupSize:]...)
-        }
-    }
+			modifiedSolution = append(modifiedSolution[:start], modifiedSolution[start+groupSize:]...)
+		}
+	}
 
-    return modifiedSolution
+	return modifiedSolution
 }
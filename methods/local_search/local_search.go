@@ -1,35 +1,73 @@
 package local_search
 
 import (
-	"fmt"
-	"math"
+	"evolutionary_computation/utils"
 	"math/rand"
-	"sort"
 )
 
+// MaxSegment is the length of the longest segment the Or-opt moves relocate.
+const MaxSegment = 3
+
 type Move struct {
 	moveType string
 	i, j     int // indices of nodes involved
+	// A segment relocation moves the segment of length nodes from position i
+	// to after position j, reversed or not; the 3-opt exchanges move longer ones
+	length   int
+	reversed bool
 }
 
-func generateMoves(solution []int, unselectedNodes []int, intraMoveType string) []Move {
+// generateMoves lists the moves of the neighbourhood in random order. When the method chooses the
+// number of nodes, the neighbourhood also inserts and removes nodes within the selection range.
+func generateMoves(tour *utils.Tour, intraMoveType string, selection utils.Selection, rng *rand.Rand) []Move {
 	var moves []Move
-	n := len(solution)
+	n := tour.Len()
+	unselectedNodes := tour.Unselected()
+	if n < 4 {
+		intraMoveType = "" // all orders of up to 3 nodes are the same cycle
+	}
 
 	// Intra-route: two-nodes exchange
 	if intraMoveType == "NodeExchange" {
 		for i := 0; i < n; i++ {
 			for j := i + 1; j < n; j++ {
-				moves = append(moves, Move{"twoNodesExchange", i, j})
+				moves = append(moves, Move{moveType: "twoNodesExchange", i: i, j: j})
 			}
 		}
 	}
 
-	// Intra-route: two-edges exchange
-	if intraMoveType == "EdgeExchange" {
+	// Intra-route: two-edges exchange (2-opt), also part of Or2Opt
+	if intraMoveType == "EdgeExchange" || intraMoveType == "Or2Opt" {
 		for i := 0; i < n; i++ {
 			for j := i + 2; j < n; j++ {
-				moves = append(moves, Move{"twoEdgesExchange", i, j})
+				moves = append(moves, Move{moveType: "twoEdgesExchange", i: i, j: j})
+			}
+		}
+	}
+
+	// Intra-route: relocation of a segment of up to MaxSegment nodes, reversed or not (Or-opt)
+	if intraMoveType == "OrOpt" || intraMoveType == "Or2Opt" {
+		for i := 0; i < n; i++ {
+			for length := 1; length <= MaxSegment; length++ {
+				for offset := length; offset <= n-2; offset++ {
+					moves = append(moves, Move{moveType: "segmentRelocation", i: i, j: (i + offset) % n, length: length})
+					if length > 1 {
+						moves = append(moves, Move{moveType: "segmentRelocation", i: i, j: (i + offset) % n, length: length, reversed: true})
+					}
+				}
+			}
+		}
+	}
+
+	// Intra-route: exchange of two consecutive segments, the restricted 3-opt that removes three
+	// edges and reconnects the segments without reversing any. Removing the edges after positions
+	// p < q < r moves the segment from p+1 to q to after r.
+	if intraMoveType == "ThreeOpt" {
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				for r := q + 1; r < n; r++ {
+					moves = append(moves, Move{moveType: "segmentRelocation", i: p + 1, j: r, length: q - p})
+				}
 			}
 		}
 	}
@@ -37,403 +75,333 @@ func generateMoves(solution []int, unselectedNodes []int, intraMoveType string)
 	// Inter-route: exchange between selected and unselected nodes
 	for i := 0; i < n; i++ {
 		for _, unselected := range unselectedNodes {
-			moves = append(moves, Move{"interRouteExchange", i, unselected})
+			moves = append(moves, Move{moveType: "interRouteExchange", i: i, j: unselected})
 		}
 	}
 
-	rand.Shuffle(len(moves), func(i, j int) { moves[i], moves[j] = moves[j], moves[i] })
+	// Insert an unselected node after position i, or remove the node at position i
+	if n < selection.Max {
+		for i := 0; i < n; i++ {
+			for _, unselected := range unselectedNodes {
+				moves = append(moves, Move{moveType: "insertNode", i: i, j: unselected})
+			}
+		}
+	}
+	if n > selection.Min {
+		for i := 0; i < n; i++ {
+			moves = append(moves, Move{moveType: "removeNode", i: i, j: -1})
+		}
+	}
+
+	rng.Shuffle(len(moves), func(i, j int) { moves[i], moves[j] = moves[j], moves[i] })
 
 	return moves
 }
 
-func deltaTwoNodesExchange(solution []int, i, j int, distanceMatrix [][]int) int {
-	n := len(solution)
+func deltaTwoNodesExchange(tour *utils.Tour, i, j int, problem *utils.Problem) int {
+	problem.Counters().Delta(utils.TwoNodesExchange)
+	n := tour.Len()
 
-	prevI := solution[(i-1+n)%n]
-	nextI := solution[(i+1)%n]
-	prevJ := solution[(j-1+n)%n]
-	nextJ := solution[(j+1)%n]
+	nodeI, nodeJ := tour.At(i), tour.At(j)
+	prevI := tour.At(i - 1)
+	nextI := tour.At(i + 1)
+	prevJ := tour.At(j - 1)
+	nextJ := tour.At(j + 1)
 
 	//if i and j are neighbours
 	if (i+1)%n == j {
-		costBefore := distanceMatrix[prevI][solution[i]] + distanceMatrix[solution[i]][solution[j]] + distanceMatrix[solution[j]][nextJ] - distanceMatrix[nextJ][nextJ]
-		costAfter := distanceMatrix[prevI][solution[j]] + distanceMatrix[solution[j]][solution[i]] + distanceMatrix[solution[i]][nextJ] - distanceMatrix[nextJ][nextJ]
+		costBefore := problem.Distance(prevI, nodeI) + problem.Distance(nodeJ, nextJ)
+		costAfter := problem.Distance(prevI, nodeJ) + problem.Distance(nodeI, nextJ)
 		return costAfter - costBefore
 	}
 	if (j+1)%n == i {
-		costBefore := distanceMatrix[prevJ][solution[j]] + distanceMatrix[solution[j]][solution[i]] + distanceMatrix[solution[i]][nextI] - distanceMatrix[nextI][nextI]
-		costAfter := distanceMatrix[prevJ][solution[i]] + distanceMatrix[solution[i]][solution[j]] + distanceMatrix[solution[j]][nextI] - distanceMatrix[nextI][nextI]
+		costBefore := problem.Distance(prevJ, nodeJ) + problem.Distance(nodeI, nextI)
+		costAfter := problem.Distance(prevJ, nodeI) + problem.Distance(nodeJ, nextI)
 		return costAfter - costBefore
 	}
 
-	costBefore := distanceMatrix[prevI][solution[i]] + distanceMatrix[solution[i]][nextI] - distanceMatrix[nextI][nextI] +
-		distanceMatrix[prevJ][solution[j]] + distanceMatrix[solution[j]][nextJ] - distanceMatrix[nextJ][nextJ]
+	costBefore := problem.Distance(prevI, nodeI) + problem.Distance(nodeI, nextI) +
+		problem.Distance(prevJ, nodeJ) + problem.Distance(nodeJ, nextJ)
 
-	costAfter := distanceMatrix[prevI][solution[j]] + distanceMatrix[solution[j]][nextI] - distanceMatrix[nextI][nextI] +
-		distanceMatrix[prevJ][solution[i]] + distanceMatrix[solution[i]][nextJ] - distanceMatrix[nextJ][nextJ]
+	costAfter := problem.Distance(prevI, nodeJ) + problem.Distance(nodeJ, nextI) +
+		problem.Distance(prevJ, nodeI) + problem.Distance(nodeI, nextJ)
 
 	return costAfter - costBefore
 }
 
-func deltaTwoEdgesExchange(solution []int, i int, j int, distanceMatrix [][]int) int {
-	n := len(solution)
-
-	nodeI := solution[i]
-	nextI := solution[(i+1)%n]
-	nodeJ := solution[j]
-	nextJ := solution[(j+1)%n]
+func deltaTwoEdgesExchange(tour *utils.Tour, i int, j int, problem *utils.Problem) int {
+	problem.Counters().Delta(utils.TwoEdgesExchange)
 
-	costBefore := distanceMatrix[nodeI][nextI] - distanceMatrix[nextI][nextI] +
-		distanceMatrix[nodeJ][nextJ] - distanceMatrix[nextJ][nextJ]
+	nodeI := tour.At(i)
+	nextI := tour.At(i + 1)
+	nodeJ := tour.At(j)
+	nextJ := tour.At(j + 1)
 
-	costAfter := distanceMatrix[nodeI][nodeJ] - distanceMatrix[nodeJ][nodeJ] +
-		distanceMatrix[nextI][nextJ] - distanceMatrix[nextJ][nextJ]
+	costBefore := problem.Distance(nodeI, nextI) + problem.Distance(nodeJ, nextJ)
+	costAfter := problem.Distance(nodeI, nodeJ) + problem.Distance(nextI, nextJ)
 
 	return costAfter - costBefore
 }
 
-// Return delta
-func deltaInterCandidate(solution []int, i int, j int, dM [][]int) int {
-	n := len(solution)
-	iInSolution := findIndex(solution, i)
-	nextI := solution[(iInSolution+1)%n]
-	nextNextI := solution[(iInSolution+2)%n]
+func deltaInterRouteExchange(tour *utils.Tour, selectedIndex int, unselectedNode int, problem *utils.Problem) int {
+	problem.Counters().Delta(utils.InterRouteExchange)
+
+	selected := tour.At(selectedIndex)
+	prevSelected := tour.At(selectedIndex - 1)
+	nextSelected := tour.At(selectedIndex + 1)
 
-	delta := dM[i][j] + dM[j][nextNextI] - dM[i][nextI] - dM[nextI][nextNextI]
+	costBefore := problem.Distance(prevSelected, selected) +
+		problem.Distance(selected, nextSelected) +
+		problem.Cost(selected)
 
-	return delta
+	costAfter := problem.Distance(prevSelected, unselectedNode) +
+		problem.Distance(unselectedNode, nextSelected) +
+		problem.Cost(unselectedNode)
+
+	return costAfter - costBefore
 }
 
-func deltaInterRouteExchange(solution []int, selectedIndex int, unselectedNode int, distanceMatrix [][]int) int {
-	n := len(solution)
+func deltaInsertNode(tour *utils.Tour, i int, unselectedNode int, problem *utils.Problem) int {
+	problem.Counters().Delta(utils.InsertNode)
 
-	prevSelected := solution[(selectedIndex-1+n)%n]
-	nextSelected := solution[(selectedIndex+1)%n]
+	return problem.InsertionCost(tour.At(i), unselectedNode, tour.At(i+1))
+}
 
-	costBefore := distanceMatrix[prevSelected][solution[selectedIndex]] +
-		distanceMatrix[solution[selectedIndex]][nextSelected] -
-		distanceMatrix[nextSelected][nextSelected]
+// deltaSegmentRelocation returns the change of the length of the cycle when the segment moves,
+// the selected nodes and so their costs stay the same.
+func deltaSegmentRelocation(tour *utils.Tour, move Move, problem *utils.Problem) int {
+	problem.Counters().Delta(utils.SegmentRelocation)
 
-	costAfter := distanceMatrix[prevSelected][unselectedNode] +
-		distanceMatrix[unselectedNode][nextSelected] -
-		distanceMatrix[nextSelected][nextSelected]
+	first, last := tour.At(move.i), tour.At(move.i+move.length-1)
+	prev, next := tour.At(move.i-1), tour.At(move.i+move.length)
+	nodeJ, nextJ := tour.At(move.j), tour.At(move.j+1)
+
+	costBefore := problem.Distance(prev, first) + problem.Distance(last, next) + problem.Distance(nodeJ, nextJ)
+	costAfter := problem.Distance(prev, next) + problem.Distance(nodeJ, first) + problem.Distance(last, nextJ)
+	if move.reversed {
+		costAfter = problem.Distance(prev, next) + problem.Distance(nodeJ, last) + problem.Distance(first, nextJ)
+	}
 
 	return costAfter - costBefore
 }
 
-func applyMove(solution []int, move Move, unselectedNodes *[]int) {
-	debugPrints := false
-	if debugPrints {
-		fmt.Println("Solution before move: ", solution)
-		fmt.Println("Move: ", solution[move.i], move.j, move.moveType)
-	}
+func deltaRemoveNode(tour *utils.Tour, i int, problem *utils.Problem) int {
+	problem.Counters().Delta(utils.RemoveNode)
+
+	return -problem.InsertionCost(tour.At(i-1), tour.At(i), tour.At(i+1))
+}
+
+// moveKinds maps the move types of Move to the kinds the counters use.
+var moveKinds = map[string]utils.MoveKind{
+	"twoNodesExchange":   utils.TwoNodesExchange,
+	"twoEdgesExchange":   utils.TwoEdgesExchange,
+	"interRouteExchange": utils.InterRouteExchange,
+	"insertNode":         utils.InsertNode,
+	"removeNode":         utils.RemoveNode,
+	"segmentRelocation":  utils.SegmentRelocation,
+}
+
+// applyMove performs the move on the tour.
+func applyMove(tour *utils.Tour, move Move, problem *utils.Problem) {
+	problem.Counters().Move(moveKinds[move.moveType])
 	switch move.moveType {
 	case "twoNodesExchange":
-		solution[move.i], solution[move.j] = solution[move.j], solution[move.i]
+		tour.Swap(move.i, move.j)
 	case "twoEdgesExchange":
-		reverseSegment(solution, move.i+1, move.j)
+		tour.Reverse(move.i+1, move.j)
 	case "interRouteExchange":
-		// Find the index of `move.j` (unique node) in `unselectedNodes`
-		jIndex := findIndex(*unselectedNodes, move.j)
-		if jIndex != -1 {
-			// Perform the swap using the found index
-			solution[move.i], (*unselectedNodes)[jIndex] = (*unselectedNodes)[jIndex], solution[move.i]
-		}
-	}
-	if debugPrints {
-		fmt.Println("Solution after move: ", solution)
-	}
-}
-
-func reverseSegment(solution []int, i, j int) {
-	for i < j {
-		solution[i], solution[j] = solution[j], solution[i]
-		i++
-		j--
+		tour.Replace(move.i, move.j)
+	case "insertNode":
+		tour.Insert(move.i+1, move.j)
+	case "removeNode":
+		tour.Remove(move.i)
+	case "segmentRelocation":
+		tour.MoveSegment(move.i, move.length, move.j, move.reversed)
 	}
 }
 
-func findIndex(slice []int, value int) int {
-	for i, v := range slice {
-		if v == value {
-			return i
-		}
+// delta returns the change of the objective the move makes.
+func delta(tour *utils.Tour, move Move, problem *utils.Problem) int {
+	switch move.moveType {
+	case "twoNodesExchange":
+		return deltaTwoNodesExchange(tour, move.i, move.j, problem)
+	case "twoEdgesExchange":
+		return deltaTwoEdgesExchange(tour, move.i, move.j, problem)
+	case "interRouteExchange":
+		return deltaInterRouteExchange(tour, move.i, move.j, problem)
+	case "insertNode":
+		return deltaInsertNode(tour, move.i, move.j, problem)
+	case "removeNode":
+		return deltaRemoveNode(tour, move.i, problem)
+	case "segmentRelocation":
+		return deltaSegmentRelocation(tour, move, problem)
 	}
-	return -1
+	return 0
 }
 
 // GreedyMove evaluates moves until an improvement is found
-func GreedyMove(solution []int, visited map[int]bool, unselectedNodes []int, distanceMatrix [][]int, intraMoveType string) ([]int, bool) {
-	moves := generateMoves(solution, unselectedNodes, intraMoveType)
-	improved := false
+func GreedyMove(tour *utils.Tour, problem *utils.Problem, intraMoveType string, rng *rand.Rand) bool {
+	moves := generateMoves(tour, intraMoveType, problem.Selection, rng)
 
 	for _, move := range moves {
-		var delta int
-		switch move.moveType {
-		case "twoNodesExchange":
-			delta = deltaTwoNodesExchange(solution, move.i, move.j, distanceMatrix)
-		case "twoEdgesExchange":
-			delta = deltaTwoEdgesExchange(solution, move.i, move.j, distanceMatrix)
-		case "interRouteExchange":
-			delta = deltaInterRouteExchange(solution, move.i, move.j, distanceMatrix)
-		}
-		if delta < 0 {
-			applyMove(solution, move, &unselectedNodes)
-			improved = true
-			return solution, improved
+		if delta(tour, move, problem) < 0 {
+			applyMove(tour, move, problem)
+			return true
 		}
 	}
 
-	return solution, improved
+	return false
 }
 
-func SteepestMove(solution []int, visited map[int]bool, unselectedNodes []int, distanceMatrix [][]int, intraMoveType string) ([]int, bool) {
-	moves := generateMoves(solution, unselectedNodes, intraMoveType)
+func SteepestMove(tour *utils.Tour, problem *utils.Problem, intraMoveType string, rng *rand.Rand) bool {
+	moves := generateMoves(tour, intraMoveType, problem.Selection, rng)
 	bestDelta := 0
-	improved := false
 	var bestMove Move
 
 	// Evaluate all moves
 	for _, move := range moves {
-		var delta int
-		switch move.moveType {
-		case "twoNodesExchange":
-			delta = deltaTwoNodesExchange(solution, move.i, move.j, distanceMatrix)
-		case "twoEdgesExchange":
-			delta = deltaTwoEdgesExchange(solution, move.i, move.j, distanceMatrix)
-		case "interRouteExchange":
-			delta = deltaInterRouteExchange(solution, move.i, move.j, distanceMatrix)
-		}
-
-		if delta < bestDelta {
+		if delta := delta(tour, move, problem); delta < bestDelta {
 			bestDelta = delta
 			bestMove = move
 		}
 	}
 
 	if bestDelta < 0 {
-		applyMove(solution, bestMove, &unselectedNodes)
-		improved = true
-		return solution, improved
+		applyMove(tour, bestMove, problem)
+		return true
 	}
 
-	return solution, improved
-}
-
-type CandidateMove struct {
-	moveType             string
-	i, j                 int
-	solutionI, solutionJ int
+	return false
 }
 
-func SteepestCandidate(solution []int,
-	visited map[int]bool,
-	unselectedNodes []int,
-	distanceMatrix [][]int,
-	moves []Move,
-) ([]int, bool) {
-
+// SteepestCandidate applies the best move that adds an edge between a node and one of its
+// candidates, see candidateMoves.
+func SteepestCandidate(tour *utils.Tour, problem *utils.Problem, candidates [][]int, intraMoveType string) bool {
 	bestDelta := 0
-	improved := false
 	var bestMove Move
-
-	for _, move := range moves {
-		var delta int
-
-		tempMove := CandidateMove{
-			moveType:  move.moveType,
-			i:         move.i,
-			j:         move.j,
-			solutionI: findIndex(solution, move.i),
-			solutionJ: findIndex(solution, move.j),
-		}
-
-		if tempMove.solutionI == -1 && tempMove.solutionJ == -1 {
-			continue
+	consider := func(move Move) {
+		if delta := delta(tour, move, problem); delta < bestDelta {
+			bestDelta = delta
+			bestMove = move
 		}
+	}
 
-		if tempMove.solutionJ == -1 {
-			// inter move here
-			tempMove.moveType = "interRouteExchange"
-			delta = deltaInterCandidate(solution, tempMove.i, tempMove.j, distanceMatrix)
-		} else if tempMove.solutionI == -1 {
-			tempMove.moveType = "interRouteExchange"
-
-			tempMove.i, tempMove.j = tempMove.j, tempMove.i
-			tempMove.solutionI, tempMove.solutionJ = tempMove.solutionJ, tempMove.solutionI
-
-			delta = deltaInterCandidate(solution, tempMove.i, tempMove.j, distanceMatrix)
-		} else {
-			// if i and j are neighbours, skip
-			if tempMove.solutionJ == (tempMove.solutionI+1)%len(solution) || tempMove.solutionI == (tempMove.solutionJ+1)%len(solution) {
-				continue
-			}
-			tempMove.moveType = "twoEdgesExchange"
-
-			delta = deltaTwoEdgesExchange(solution, tempMove.solutionI, tempMove.solutionJ, distanceMatrix)
-		}
-
-		if delta < bestDelta {
-			bestDelta = delta
-			if tempMove.moveType == "interRouteExchange" {
-				bestMove = Move{moveType: tempMove.moveType, i: (tempMove.solutionI + 1) % len(solution), j: tempMove.j}
-			} else {
-				if tempMove.solutionI > tempMove.solutionJ {
-					bestMove = Move{moveType: tempMove.moveType, i: tempMove.solutionJ, j: tempMove.solutionI}
-				} else {
-					bestMove = Move{moveType: tempMove.moveType, i: tempMove.solutionI, j: tempMove.solutionJ}
-				}
-			}
+	for i, list := range candidates {
+		for _, j := range list {
+			candidateMoves(tour, problem, candidates, i, j, intraMoveType, consider)
 		}
 	}
 
 	if bestDelta < 0 {
-		applyMove(solution, bestMove, &unselectedNodes)
-		improved = true
-		return solution, improved
+		applyMove(tour, bestMove, problem)
+		return true
 	}
 
-	return solution, improved
+	return false
 }
 
-func SteepestDelta(solution []int, visited map[int]bool, unselectedNodes []int, distanceMatrix [][]int, moves []MoveDelta) ([]int, bool) {
-	bestDelta := 0
-	improved := false
-	var bestMove MoveDelta
-	var move_i_index int
-	var move_j_index int
-	var moveType string
-	var unselected_node int
-
-	for i, move := range moves {
-		delta := move.delta
-		move_i_index = findIndex(solution, move.i)
-		move_j_index = findIndex(solution, move.j)
-
-		// removing unwanted moves (set delta to 0 in the moves list)
-		if move.moveType == "twoEdgesExchange" &&
-			((move_i_index == -1 || move_j_index == -1) || math.Abs(float64(move_i_index-move_j_index)) == 1) {
-			moves[i].delta = 0
-			continue
-		}
-		if move.moveType == "interRouteExchange" &&
-			(move_i_index == -1 || move_j_index != -1) {
-			moves[i].delta = 0
-			continue
-		}
-
-		if delta < bestDelta {
-			bestDelta = delta
-			bestMove = move
-			moveType = move.moveType
-
-			// remove move from moves and break (set delta to 0 in the moves list)
-			moves[i].delta = 0
-			break
-		}
+// candidateMoves passes the moves that add an edge between the nodes i and j to consider, with j
+// after or before i. When both are selected these are the intra-route moves of the type, two-edges
+// exchanges, segment relocations or both for Or2Opt, the segment exchanges for ThreeOpt, and the
+// removal of the node in between. Otherwise they are the exchange of the successor or the
+// predecessor of the selected one with the other, and the insertion of the other next to it.
+func candidateMoves(tour *utils.Tour, problem *utils.Problem, candidates [][]int, i, j int, intraMoveType string, consider func(Move)) {
+	n := tour.Len()
+	selectedI, selectedJ := tour.IsSelected(i), tour.IsSelected(j)
+	if !selectedI && !selectedJ {
+		return
 	}
 
-	if bestDelta < 0 {
-		if moveType == "twoEdgesExchange" {
-			// fmt.Println("Best move: ", bestMove, move_i_index, move_j_index)
-			min := int(math.Min(float64(move_i_index), float64(move_j_index)))
-			max := int(math.Max(float64(move_i_index), float64(move_j_index)))
-			applyMove(solution, Move{moveType: moveType, i: min, j: max}, &unselectedNodes)
-		} else {
-			// fmt.Println("Best move: ", bestMove, move_i_index, move_j_index)
-			unselected_before := append([]int{}, unselectedNodes...)
-			applyMove(solution, Move{moveType: moveType, i: move_i_index, j: bestMove.j}, &unselectedNodes)
-
-			// find the unselected node
-			for _, node := range unselected_before {
-				if !contains(unselectedNodes, node) {
-					unselected_node = node
+	if selectedI && selectedJ {
+		posI, posJ := tour.Pos(i), tour.Pos(j)
+		if intraMoveType == "EdgeExchange" || intraMoveType == "Or2Opt" {
+			// The edges leaving i and j, then the edges entering them, unless they share a node
+			for _, shift := range [2]int{0, -1} {
+				a, b := (posI+shift+n)%n, (posJ+shift+n)%n
+				if (a+1)%n != b && (b+1)%n != a {
+					consider(Move{moveType: "twoEdgesExchange", i: min(a, b), j: max(a, b)})
 				}
 			}
 		}
-		improved = true
-
-		// Update the moves list
-		updateMovesDelta(bestMove, moves, distanceMatrix, solution, move_i_index, move_j_index, unselected_node)
-
-		return solution, improved
+		if intraMoveType == "OrOpt" || intraMoveType == "Or2Opt" {
+			candidateRelocations(n, posI, posJ, consider)
+			candidateRelocations(n, posJ, posI, consider)
+		}
+		if intraMoveType == "ThreeOpt" {
+			candidateExchanges(tour, candidates, i, j, consider)
+		}
+		if n > problem.Selection.Min && n > 3 {
+			if tour.Next(tour.Next(i)) == j {
+				consider(Move{moveType: "removeNode", i: (posI + 1) % n, j: -1})
+			}
+			if tour.Prev(tour.Prev(i)) == j {
+				consider(Move{moveType: "removeNode", i: (posI - 1 + n) % n, j: -1})
+			}
+		}
+		return
 	}
 
-	return solution, improved
+	selected, unselected := i, j
+	if !selectedI {
+		selected, unselected = j, i
+	}
+	pos := tour.Pos(selected)
+	consider(Move{moveType: "interRouteExchange", i: (pos + 1) % n, j: unselected})
+	consider(Move{moveType: "interRouteExchange", i: (pos - 1 + n) % n, j: unselected})
+	if n < problem.Selection.Max {
+		consider(Move{moveType: "insertNode", i: pos, j: unselected})
+		consider(Move{moveType: "insertNode", i: (pos - 1 + n) % n, j: unselected})
+	}
 }
 
-func updateMovesDelta(bestMove MoveDelta, moves []MoveDelta, distanceMatrix [][]int, solution []int, move_i_index int, move_j_index int, unselected_node int) {
-	var NodestoCheck []int
-	if bestMove.moveType == "twoEdgesExchange" {
-		min := int(math.Min(float64(move_i_index), float64(move_j_index)))
-		max := int(math.Max(float64(move_i_index), float64(move_j_index)))
-		min = int(math.Max(float64(min-1), 0))
-		max = int(math.Min(float64(max+2), float64(len(solution))))
-		for i := min; i < max; i++ {
-			NodestoCheck = append(NodestoCheck, solution[i])
+// candidateRelocations passes the segment relocations that put the node at position y next to
+// the node at position x to consider, moving a segment that starts or ends at y to after or before x.
+func candidateRelocations(n, x, y int, consider func(Move)) {
+	for length := 1; length <= MaxSegment; length++ {
+		starting, ending := y, (y-length+1+n)%n
+		after, before := x, (x-1+n)%n
+		for _, move := range [4]Move{
+			{moveType: "segmentRelocation", i: starting, j: after, length: length},
+			{moveType: "segmentRelocation", i: ending, j: before, length: length},
+			{moveType: "segmentRelocation", i: starting, j: before, length: length, reversed: true},
+			{moveType: "segmentRelocation", i: ending, j: after, length: length, reversed: true},
+		} {
+			// The segment can't hold x nor stay in place, a single node is the same reversed
+			if offset := (move.j - move.i + n) % n; offset >= length && offset <= n-2 && !(length == 1 && move.reversed) {
+				consider(move)
+			}
 		}
-	} else {
-		j_index := findIndex(solution, bestMove.j)
-		nextJ := solution[(j_index+1)%len(solution)]
-		prevJ := solution[(j_index-1+len(solution))%len(solution)]
-		NodestoCheck = []int{prevJ, bestMove.j, nextJ}
 	}
+}
 
-	for M, move := range moves {
-		if move.moveType == "twoEdgesExchange" &&
-			(contains(NodestoCheck, move.i) && contains(solution, move.j)) ||
-			(contains(NodestoCheck, move.j) && contains(solution, move.i)) {
-			//check if i and j are not neighbours
-			i_index := findIndex(solution, move.i)
-			j_index := findIndex(solution, move.j)
-
-			min := int(math.Min(float64(i_index), float64(j_index)))
-			max := int(math.Max(float64(i_index), float64(j_index)))
-
-			if math.Abs(float64(i_index-j_index)) != 1 {
-				delta := deltaTwoEdgesExchange(solution, min, max, distanceMatrix)
-				if delta < 0 {
-					moves[M].delta = delta
-				} else {
-					moves[M].delta = 0
-				}
-			} else {
-				moves[M].delta = 0
-			}
-		}
-		if move.moveType == "interRouteExchange" {
-			if contains(NodestoCheck, move.i) && !contains(solution, move.j) {
-				i_index := findIndex(solution, move.i)
-				delta := deltaInterRouteExchange(solution, i_index, move.j, distanceMatrix)
-				if delta < 0 {
-					moves[M].delta = delta
-				} else {
-					moves[M].delta = 0
-				}
-			}
-			if contains(solution, move.i) && unselected_node == move.j {
-				i_index := findIndex(solution, move.i)
-				delta := deltaInterRouteExchange(solution, i_index, move.j, distanceMatrix)
-				if delta < 0 {
-					moves[M].delta = delta
-				} else {
-					moves[M].delta = 0
-				}
-			}
+// candidateExchanges passes the segment exchanges that put the node y right after or before the
+// node x to consider. Of the two other edges each adds, one goes from the node before y, or
+// before x, to one of its candidates, so that there are at most two exchanges per candidate.
+func candidateExchanges(tour *utils.Tour, candidates [][]int, x, y int, consider func(Move)) {
+	n := tour.Len()
+	posX, posY := tour.Pos(x), tour.Pos(y)
+
+	// The edges after p, q and r are removed, the ones from p to q+1, from r to p+1 and from q to
+	// r+1 added, so that the segment from p+1 to q moves to after r
+	exchange := func(p, q, r int) {
+		length, offset := (q-p+n)%n, (r-p+n)%n
+		if length > 0 && length < offset {
+			consider(Move{moveType: "segmentRelocation", i: (p + 1) % n, j: r, length: length})
 		}
 	}
 
-	// Sort moves by delta in ascending order
-	sort.Slice(moves, func(a, b int) bool {
-		return moves[a].delta < moves[b].delta
-	})
-}
-
-func contains(slice []int, value int) bool {
-	for _, v := range slice {
-		if v == value {
-			return true
+	// y after x: the edge from x to y, and from the node before y to a candidate
+	p, q := posX, (posY-1+n)%n
+	for _, node := range candidates[tour.At(q)] {
+		if tour.IsSelected(node) {
+			exchange(p, q, (tour.Pos(node)-1+n)%n)
+		}
+	}
+	// y before x: the edge from y to x, and from the node before x to a candidate
+	p, r := (posX-1+n)%n, posY
+	for _, node := range candidates[tour.At(p)] {
+		if tour.IsSelected(node) {
+			exchange(p, (tour.Pos(node)-1+n)%n, r)
 		}
 	}
-	return false
 }
@@ -0,0 +1,80 @@
+package local_search
+
+import (
+	"evolutionary_computation/methods"
+	"evolutionary_computation/utils"
+	"math/rand"
+	"testing"
+)
+
+// checkDelta fails the test unless the delta of the move is the change of the fitness it makes.
+func checkDelta(t *testing.T, tour *utils.Tour, move Move, problem *utils.Problem) {
+	t.Helper()
+	before := problem.Fitness(tour.Order())
+	predicted := delta(tour, move, problem)
+	after := tour.Clone()
+	applyMove(after, move, problem)
+	if err := utils.ValidateSolution(after.Order(), problem.NumNodes(), problem.Selection); err != nil {
+		t.Fatalf("%+v on %v: %v", move, tour.Order(), err)
+	}
+	if actual := problem.Fitness(after.Order()) - before; predicted != actual {
+		t.Fatalf("%+v on %v: delta %d, the fitness changes by %d", move, tour.Order(), predicted, actual)
+	}
+}
+
+func TestDeltas(t *testing.T) {
+	tests := []struct {
+		name          string
+		intraMoveType string
+		selection     utils.Selection
+		weights       utils.Weights
+	}{
+		{"node exchange", "NodeExchange", utils.HalfSelection(20), utils.UnitWeights},
+		{"edge exchange", "EdgeExchange", utils.HalfSelection(20), utils.UnitWeights},
+		{"node exchange, free", "NodeExchange", utils.Selection{Min: 3, Max: 20}, utils.UnitWeights},
+		{"edge exchange, free", "EdgeExchange", utils.Selection{Min: 3, Max: 20}, utils.UnitWeights},
+		{"edge exchange, weighted", "EdgeExchange", utils.Selection{Min: 3, Max: 20}, utils.Weights{Alpha: 2, Beta: 3}},
+		{"or-opt", "OrOpt", utils.HalfSelection(20), utils.UnitWeights},
+		{"or-2opt", "Or2Opt", utils.HalfSelection(20), utils.UnitWeights},
+		{"or-2opt, free", "Or2Opt", utils.Selection{Min: 3, Max: 20}, utils.Weights{Alpha: 2, Beta: 3}},
+		{"3-opt", "ThreeOpt", utils.HalfSelection(20), utils.UnitWeights},
+		{"3-opt, free", "ThreeOpt", utils.Selection{Min: 3, Max: 20}, utils.Weights{Alpha: 2, Beta: 3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problem := newTestProblem(t, 1, 20, -300, test.selection, test.weights)
+			rng := rand.New(rand.NewSource(2))
+			for sample := 0; sample < 20; sample++ {
+				tour := utils.NewTour(problem.NumNodes(), methods.RandomSolution(problem, 0, rng))
+				for _, move := range generateMoves(tour, test.intraMoveType, problem.Selection, rng) {
+					checkDelta(t, tour, move, problem)
+				}
+			}
+		})
+	}
+}
+
+func TestDeltaSegmentRelocation(t *testing.T) {
+	problem := newTestProblem(t, 6, 12, 0, utils.Selection{Min: 8, Max: 8}, utils.UnitWeights)
+	tour := utils.NewTour(problem.NumNodes(), []int{4, 9, 0, 7, 2, 11, 5, 3})
+	tests := []struct {
+		name string
+		move Move
+	}{
+		{"single node", Move{moveType: "segmentRelocation", i: 1, j: 4, length: 1}},
+		{"to the previous edge", Move{moveType: "segmentRelocation", i: 2, j: 0, length: 2}},
+		{"to the next edge", Move{moveType: "segmentRelocation", i: 2, j: 5, length: 3}},
+		{"reversed", Move{moveType: "segmentRelocation", i: 3, j: 7, length: 3, reversed: true}},
+		{"wrapping around", Move{moveType: "segmentRelocation", i: 6, j: 3, length: 3}},
+		{"wrapping around, reversed", Move{moveType: "segmentRelocation", i: 7, j: 1, length: 2, reversed: true}},
+		{"to the edge wrapping around", Move{moveType: "segmentRelocation", i: 2, j: 7, length: 3, reversed: true}},
+		{"segment exchange", Move{moveType: "segmentRelocation", i: 1, j: 6, length: 4}},
+		{"segment exchange wrapping around", Move{moveType: "segmentRelocation", i: 5, j: 2, length: 4}},
+		{"segment exchange with a single node", Move{moveType: "segmentRelocation", i: 3, j: 1, length: 6}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkDelta(t, tour, test.move, problem)
+		})
+	}
+}
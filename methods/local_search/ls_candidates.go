@@ -1,64 +1,57 @@
 package local_search
 
 import (
+	"context"
 	"evolutionary_computation/methods"
-	"sort"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"fmt"
+	"math/rand"
 )
 
-func LS_Candidates(distanceMatrix [][]int, startNode int) []int {
-	// Run random function to get the initial solution
-	solution := methods.RandomSolution(distanceMatrix, startNode)
-	visted := make(map[int]bool)
-	// Make visited map
-	for _, id := range solution {
-		visted[id] = true
-	}
-
-	// Make unselected list out of all nodes from matrix without selected nodes
-	m := len(distanceMatrix)
-	unselected := make([]int, 0, m)
-	for i := 0; i < m; i++ {
-		if _, ok := visted[i]; !ok {
-			unselected = append(unselected, i)
+func init() {
+	for k, list := range CandidateLists {
+		name := "LS_candidates"
+		if k > 0 {
+			name += "_" + list.Name
+		}
+		// The two-edges exchanges of the original method, the segment relocations, both, and the
+		// segment exchanges
+		for suffix, intraMoveType := range map[string]string{"": "EdgeExchange", "_oropt": "OrOpt", "_or2opt": "Or2Opt", "_3opt": "ThreeOpt"} {
+			solver.Register(candidatesSolver{solver.NewMethod(name+suffix, func(ctx context.Context, run solver.Run) []int {
+				return LS_Candidates(ctx, run.Problem, run.StartNode, list, run.Params.Int("candidates"), intraMoveType, run.Rng)
+			},
+				solver.Param{Name: "candidates", Type: solver.Int, Default: 10, Min: 1, Max: 10000, Usage: "number of candidates kept for each node, " + list.Usage},
+			), list})
 		}
 	}
+}
 
-	moves := getCandidateMoves(distanceMatrix, 10)
-	// Run local search function as long as there is improvement
-	improved := true
-	// for k:=0; k<5 && improved; k++ {
-	for improved {
-		solution, improved = SteepestCandidate(solution, visted, unselected, distanceMatrix, moves)
+// candidatesSolver runs LS_Candidates, which needs the positions of the nodes for the geometric lists.
+type candidatesSolver struct {
+	solver.Solver
+	list CandidateList
+}
 
+func (s candidatesSolver) Supports(problem *utils.Problem) error {
+	if s.list.Geometric && problem.Points == nil {
+		return fmt.Errorf("the %s candidates need the positions of the nodes, the instance has only edge weights", s.list.Name)
 	}
-	return solution
+	return nil
 }
 
-func getCandidateMoves(distanceMatrix [][]int, N int) []Move {
-	var moves []Move
-
-	for i, row := range distanceMatrix {
-		// Collect indices and values of each row, ignoring the diagonal (i == j)
-		type IndexedValue struct {
-			j, value int
-		}
-		var indexedValues []IndexedValue
-		for j, value := range row {
-			if i != j { // Ignore diagonal
-				indexedValues = append(indexedValues, IndexedValue{j, value})
-			}
-		}
-
-		// Sort based on values in ascending order
-		sort.Slice(indexedValues, func(a, b int) bool {
-			return indexedValues[a].value < indexedValues[b].value
-		})
+// LS_Candidates is the steepest local search restricted to the moves that introduce an edge between
+// a node and one of its numCandidates candidates from the list, see SteepestCandidate.
+func LS_Candidates(ctx context.Context, problem *utils.Problem, startNode int, list CandidateList, numCandidates int, intraMoveType string, rng *rand.Rand) []int {
+	problem.Counters().LocalSearch()
+	// Run random function to get the initial solution
+	tour := utils.NewTour(problem.NumNodes(), methods.RandomSolution(problem, startNode, rng))
 
-		// Select the N smallest values and create Moves
-		for k := 0; k < N && k < len(indexedValues); k++ {
-			moves = append(moves, Move{"", i, indexedValues[k].j})
-		}
+	candidates := list.Build(problem, numCandidates)
+	// Run local search function as long as there is improvement
+	improved := true
+	for improved && ctx.Err() == nil {
+		improved = SteepestCandidate(tour, problem, candidates, intraMoveType)
 	}
-
-	return moves
+	return tour.Solution()
 }
@@ -0,0 +1,113 @@
+package local_search
+
+import (
+	"evolutionary_computation/methods"
+	"evolutionary_computation/utils"
+	"math/rand"
+	"testing"
+)
+
+// neighbours tells whether b comes right after or before a in the tour.
+func neighbours(tour *utils.Tour, a, b int) bool {
+	return tour.IsSelected(a) && tour.IsSelected(b) && (tour.Next(a) == b || tour.Prev(a) == b)
+}
+
+func TestCandidateMoves(t *testing.T) {
+	tests := []struct {
+		name          string
+		intraMoveType string
+		selection     utils.Selection
+	}{
+		{"half", "EdgeExchange", utils.HalfSelection(16)},
+		{"free", "EdgeExchange", utils.Selection{Min: 3, Max: 16}},
+		{"free within a range", "EdgeExchange", utils.Selection{Min: 6, Max: 10}},
+		{"or-opt", "OrOpt", utils.HalfSelection(16)},
+		{"or-2opt, free", "Or2Opt", utils.Selection{Min: 3, Max: 16}},
+		{"3-opt", "ThreeOpt", utils.HalfSelection(16)},
+		{"3-opt, free", "ThreeOpt", utils.Selection{Min: 3, Max: 16}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problem := newTestProblem(t, 4, 16, -200, test.selection, utils.UnitWeights)
+			// Every node is a candidate of every other, so that the segment exchanges have both sides
+			candidates := make([][]int, problem.NumNodes())
+			for node := range candidates {
+				for other := 0; other < problem.NumNodes(); other++ {
+					if other != node {
+						candidates[node] = append(candidates[node], other)
+					}
+				}
+			}
+			rng := rand.New(rand.NewSource(5))
+			for sample := 0; sample < 20; sample++ {
+				tour := utils.NewTour(problem.NumNodes(), methods.RandomSolution(problem, 0, rng))
+				for i := 0; i < problem.NumNodes(); i++ {
+					for j := 0; j < problem.NumNodes(); j++ {
+						if i == j || (!tour.IsSelected(i) && !tour.IsSelected(j)) || neighbours(tour, i, j) {
+							continue
+						}
+						// The other node goes after the selected one when the selected one keeps
+						// its predecessor, and before it when it keeps its successor
+						selected, other := i, j
+						if !tour.IsSelected(i) {
+							selected, other = j, i
+						}
+						prev, next := tour.Prev(selected), tour.Next(selected)
+
+						var after, before int
+						candidateMoves(tour, problem, candidates, i, j, test.intraMoveType, func(move Move) {
+							checkDelta(t, tour, move, problem)
+							result := tour.Clone()
+							applyMove(result, move, problem)
+							if !neighbours(result, selected, other) {
+								t.Fatalf("%+v on %v leaves %d and %d apart in %v", move, tour.Order(), i, j, result.Order())
+							}
+							if prev != other && neighbours(result, selected, prev) {
+								after++
+							}
+							if next != other && neighbours(result, selected, next) {
+								before++
+							}
+						})
+						if after == 0 || before == 0 {
+							t.Fatalf("%d with %d on %v: %d moves put %d after %d and %d before it", i, j, tour.Order(), after, other, selected, before)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCandidateRelocations(t *testing.T) {
+	for _, n := range []int{5, 6, 9} {
+		order := make([]int, n)
+		for k := range order {
+			order[k] = k
+		}
+		tour := utils.NewTour(n, order)
+		for x := 0; x < n; x++ {
+			for y := 0; y < n; y++ {
+				if y == x || y == (x+1)%n || x == (y+1)%n {
+					continue
+				}
+				var after, before int
+				candidateRelocations(n, x, y, func(move Move) {
+					result := tour.Clone()
+					result.MoveSegment(move.i, move.length, move.j, move.reversed)
+					switch {
+					case result.Next(x) == y:
+						after++
+					case result.Prev(x) == y:
+						before++
+					default:
+						t.Fatalf("%d nodes, %+v leaves %d and %d apart in %v", n, move, x, y, result.Order())
+					}
+				})
+				if after < 2 || before < 2 {
+					t.Fatalf("%d nodes: %d relocations put %d after %d and %d before it", n, after, y, x, before)
+				}
+			}
+		}
+	}
+}
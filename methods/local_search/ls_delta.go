@@ -1,97 +1,317 @@
 package local_search
 
 import (
+	"container/heap"
+	"context"
 	"evolutionary_computation/methods"
-	"math"
-	"sort"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"math/rand"
 )
 
-func LS_Delta(distanceMatrix [][]int, startNode int) []int {
+func init() {
+	solver.Register(solver.NewMethod("LS_delta", func(ctx context.Context, run solver.Run) []int {
+		return LS_Delta(ctx, run.Problem, run.StartNode, run.Rng)
+	}))
+}
+
+// LS_Delta is the steepest local search of RandomSteepestIntraEdge, which exchanges two edges and
+// selected with unselected nodes, and inserts and removes nodes when the method chooses the size.
+// Instead of evaluating the whole neighbourhood in every step it keeps the list of improving moves,
+// see improvingMoves, and reaches the same local optima up to ties between equal deltas.
+func LS_Delta(ctx context.Context, problem *utils.Problem, startNode int, rng *rand.Rand) []int {
+	problem.Counters().LocalSearch()
 	// Run random function to get the initial solution
-	solution := methods.RandomSolution(distanceMatrix, startNode)
-	visted := make(map[int]bool)
-	// Make visited map
-	for _, id := range solution {
-		visted[id] = true
+	lm := newImprovingMoves(problem, methods.RandomSolution(problem, startNode, rng))
+	for ctx.Err() == nil && lm.step() {
 	}
+	return lm.tour.Solution()
+}
+
+// lmMove is a move of the list of improving moves. It is keyed by the edges it removes, so that
+// it can be recognized after other moves changed the solution:
+//   - TwoEdgesExchange removes the edges a→b and c→d, in this direction, and adds a-c and b-d
+//   - InterRouteExchange replaces the node b between a and c with the unselected node d
+//   - InsertNode inserts the unselected node d into the edge a-b
+//   - RemoveNode removes the node b between a and c
+type lmMove struct {
+	kind       utils.MoveKind
+	a, b, c, d int
+	delta      int
+	seq        int // order of evaluation, ties between equal deltas go to the earlier move
+}
+
+// moveStatus tells what becomes of a stored move in the current solution.
+type moveStatus int
+
+const (
+	applicable moveStatus = iota
+	// notApplicable moves keep their delta but can't be applied now: the edges of a two-edges
+	// exchange are traversed in opposite directions, or the solution has no room for an insertion
+	// or removal. A later move can make them applicable again, so they stay in the list.
+	notApplicable
+	// invalid moves remove an edge that is gone or insert a node that was selected, they are dropped.
+	invalid
+)
+
+type moveQueue []lmMove
+
+func (q moveQueue) Len() int { return len(q) }
+func (q moveQueue) Less(i, j int) bool {
+	return q[i].delta < q[j].delta || (q[i].delta == q[j].delta && q[i].seq < q[j].seq)
+}
+func (q moveQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *moveQueue) Push(x any)   { *q = append(*q, x.(lmMove)) }
+func (q *moveQueue) Pop() any {
+	old := *q
+	m := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return m
+}
+
+// improvingMoves is a steepest local search with a list of improving moves, ordered by delta.
+// A delta depends only on the edges and nodes of the move, so it stays valid while they are
+// in the solution. Each step applies the best applicable move of the list, after which only the
+// moves with the new edges and with the nodes whose neighbours or selection changed are evaluated.
+// Two-edges exchanges are evaluated in both orientations of the second edge, the one that doesn't
+// apply now can become applicable when a later move reverses one of the edges.
+type improvingMoves struct {
+	problem *utils.Problem
+	tour    *utils.Tour
+	queue   moveQueue
+	seq     int
+}
+
+func newImprovingMoves(problem *utils.Problem, solution []int) *improvingMoves {
+	lm := &improvingMoves{problem: problem, tour: utils.NewTour(problem.NumNodes(), solution)}
 
-	// Make unselected list out of all nodes from matrix without selected nodes
-	m := len(distanceMatrix)
-	unselected := make([]int, 0, m)
-	for i := 0; i < m; i++ {
-		if _, ok := visted[i]; !ok {
-			unselected = append(unselected, i)
+	n := lm.tour.Len()
+	for i := 0; i < n; i++ {
+		a, b := lm.tour.At(i), lm.tour.At(i+1)
+		// Every pair of edges once, the edges of each pair must not share a node
+		for j := i + 2; j < n && (i > 0 || j < n-1); j++ {
+			lm.addTwoEdgesExchanges(a, b, lm.tour.At(j), lm.tour.At(j+1))
 		}
+		lm.addInsertions(a, b)
+		lm.addExchangesOf(b)
 	}
+	return lm
+}
 
-	moves := getMovesDelta(distanceMatrix, solution)
-
-	improved := true
-	for improved {
-		solution, improved = SteepestDelta(solution, visted, unselected, distanceMatrix, moves)
-		continue
+// direction returns 1 if the solution traverses the edge a→b, -1 if it traverses it as b→a
+// and 0 if the edge is not in the solution.
+func (lm *improvingMoves) direction(a, b int) int {
+	switch {
+	case !lm.tour.IsSelected(a) || !lm.tour.IsSelected(b):
+		return 0
+	case lm.tour.Next(a) == b:
+		return 1
+	case lm.tour.Next(b) == a:
+		return -1
 	}
-	return solution
+	return 0
 }
 
-type MoveDelta struct {
-	moveType string
-	i, j     int // indices of nodes involved
-	delta    int // change in cost
+func (lm *improvingMoves) push(m lmMove) {
+	if m.delta < 0 {
+		m.seq = lm.seq
+		lm.seq++
+		heap.Push(&lm.queue, m)
+	}
 }
 
-func getMovesDelta(distanceMatrix [][]int, solution []int) []MoveDelta {
-	var moves []MoveDelta
-	n := len(distanceMatrix)
+// addTwoEdgesExchanges stores the exchanges of the edge a→b with c-d in both orientations.
+func (lm *improvingMoves) addTwoEdgesExchanges(a, b, c, d int) {
+	p := lm.problem
+	removed := p.Distance(a, b) + p.Distance(c, d)
+	p.Counters().Delta(utils.TwoEdgesExchange)
+	lm.push(lmMove{kind: utils.TwoEdgesExchange, a: a, b: b, c: c, d: d, delta: p.Distance(a, c) + p.Distance(b, d) - removed})
+	p.Counters().Delta(utils.TwoEdgesExchange)
+	lm.push(lmMove{kind: utils.TwoEdgesExchange, a: a, b: b, c: d, d: c, delta: p.Distance(a, d) + p.Distance(b, c) - removed})
+}
 
-	// Generate all combinations of moves and add None as delta
+// addTwoEdgesExchangesOf stores the exchanges of the edge a→b with every edge of the solution
+// it shares no node with, except the edges in skip.
+func (lm *improvingMoves) addTwoEdgesExchangesOf(a, b int, skip [][2]int) {
+	n := lm.tour.Len()
+	if n < 4 {
+		return
+	}
+edges:
 	for i := 0; i < n; i++ {
-		for j := 0; j < n; j++ {
-			if i != j {
-				moves = append(moves, MoveDelta{"interRouteExchange", i, j, 0})
-				if i < j {
-					moves = append(moves, MoveDelta{"twoEdgesExchange", i, j, 0})
-				}
+		c, d := lm.tour.At(i), lm.tour.At(i+1)
+		if c == a || c == b || d == a || d == b {
+			continue
+		}
+		for _, e := range skip {
+			if (e[0] == c && e[1] == d) || (e[0] == d && e[1] == c) {
+				continue edges
 			}
 		}
+		lm.addTwoEdgesExchanges(a, b, c, d)
 	}
+}
 
-	for M, move := range moves {
-		move_i_index := findIndex(solution, moves[M].i)
-		move_j_index := findIndex(solution, moves[M].j)
+// addExchangesOf stores the exchanges of the selected node with every unselected node, and its removal.
+func (lm *improvingMoves) addExchangesOf(node int) {
+	p := lm.problem
+	prev, next := lm.tour.Prev(node), lm.tour.Next(node)
+	current := p.InsertionCost(prev, node, next)
+	for _, u := range lm.tour.Unselected() {
+		p.Counters().Delta(utils.InterRouteExchange)
+		lm.push(lmMove{kind: utils.InterRouteExchange, a: prev, b: node, c: next, d: u, delta: p.InsertionCost(prev, u, next) - current})
+	}
+	if !p.Selection.Fixed() {
+		p.Counters().Delta(utils.RemoveNode)
+		lm.push(lmMove{kind: utils.RemoveNode, a: prev, b: node, c: next, delta: -current})
+	}
+}
 
-		//if i and j are in solution and not neighbours
-		// calculate TwoEdgesExchange delata
-		if move.moveType == "twoEdgesExchange" &&
-			move_i_index != -1 &&
-			move_j_index != -1 &&
-			math.Abs(float64(move_i_index-move_j_index)) != 1 {
-			min := int(math.Min(float64(move_i_index), float64(move_j_index)))
-			max := int(math.Max(float64(move_i_index), float64(move_j_index)))
-			delta := deltaTwoEdgesExchange(solution, min, max, distanceMatrix)
-			if delta < 0 {
-				moves[M].delta = delta
+// addExchangesWith stores the exchanges of every selected node with the unselected one and its
+// insertions into every edge, except the selected nodes in skip whose exchanges are stored anyway.
+func (lm *improvingMoves) addExchangesWith(u int, skip []int) {
+	p := lm.problem
+nodes:
+	for _, node := range lm.tour.Order() {
+		for _, s := range skip {
+			if s == node {
+				continue nodes
 			}
+		}
+		prev, next := lm.tour.Prev(node), lm.tour.Next(node)
+		p.Counters().Delta(utils.InterRouteExchange)
+		lm.push(lmMove{kind: utils.InterRouteExchange, a: prev, b: node, c: next, d: u,
+			delta: p.InsertionCost(prev, u, next) - p.InsertionCost(prev, node, next)})
+	}
+	if !p.Selection.Fixed() {
+		n := lm.tour.Len()
+		for i := 0; i < n; i++ {
+			a, b := lm.tour.At(i), lm.tour.At(i+1)
+			p.Counters().Delta(utils.InsertNode)
+			lm.push(lmMove{kind: utils.InsertNode, a: a, b: b, d: u, delta: p.InsertionCost(a, u, b)})
+		}
+	}
+}
 
+// addInsertions stores the insertions of every unselected node into the edge a-b.
+func (lm *improvingMoves) addInsertions(a, b int) {
+	p := lm.problem
+	if p.Selection.Fixed() {
+		return
+	}
+	for _, u := range lm.tour.Unselected() {
+		p.Counters().Delta(utils.InsertNode)
+		lm.push(lmMove{kind: utils.InsertNode, a: a, b: b, d: u, delta: p.InsertionCost(a, u, b)})
+	}
+}
+
+func (lm *improvingMoves) status(m lmMove) moveStatus {
+	n := lm.tour.Len()
+	switch m.kind {
+	case utils.TwoEdgesExchange:
+		first, second := lm.direction(m.a, m.b), lm.direction(m.c, m.d)
+		if first == 0 || second == 0 {
+			return invalid
+		}
+		if first != second {
+			return notApplicable
 		}
+	case utils.InterRouteExchange:
+		if lm.tour.IsSelected(m.d) || lm.direction(m.a, m.b) == 0 || lm.direction(m.b, m.c) == 0 {
+			return invalid
+		}
+	case utils.InsertNode:
+		if lm.tour.IsSelected(m.d) || lm.direction(m.a, m.b) == 0 {
+			return invalid
+		}
+		if n >= lm.problem.Selection.Max {
+			return notApplicable
+		}
+	case utils.RemoveNode:
+		if lm.direction(m.a, m.b) == 0 || lm.direction(m.b, m.c) == 0 {
+			return invalid
+		}
+		if n <= lm.problem.Selection.Min {
+			return notApplicable
+		}
+	}
+	return applicable
+}
 
-		//if i is in solution and j is not
-		// calculate InterRouteExchange delta
-		if move.moveType == "interRouteExchange" &&
-			move_i_index != -1 &&
-			move_j_index == -1 {
-			delta := deltaInterRouteExchange(solution, move_i_index, moves[M].j, distanceMatrix)
-			if delta < 0 {
-				moves[M].delta = delta
-			}
+// step applies the best applicable move of the list and stores the new improving moves.
+// It returns false in a local optimum, when no stored move is applicable.
+func (lm *improvingMoves) step() bool {
+	var skipped []lmMove
+	defer func() {
+		for _, m := range skipped {
+			heap.Push(&lm.queue, m)
+		}
+	}()
 
+	for lm.queue.Len() > 0 {
+		m := heap.Pop(&lm.queue).(lmMove)
+		switch lm.status(m) {
+		case notApplicable:
+			skipped = append(skipped, m)
+		case applicable:
+			lm.apply(m)
+			return true
 		}
 	}
+	return false
+}
+
+// apply performs the move and stores the moves it makes possible.
+func (lm *improvingMoves) apply(m lmMove) {
+	lm.problem.Counters().Move(m.kind)
+	var added [][2]int // new edges, in the direction of the solution
+	var changed []int  // selected nodes whose neighbours changed
+	freed := -1        // node the move unselected
 
-	// Sort based on delta in ascending order
-	sort.Slice(moves, func(a, b int) bool {
-		return moves[a].delta < moves[b].delta
-	})
+	switch m.kind {
+	case utils.TwoEdgesExchange:
+		a, b, c := m.a, m.b, m.c
+		if lm.direction(a, b) == -1 {
+			a, b, c = b, a, m.d // both edges are traversed backwards
+		}
+		i, j := lm.tour.Pos(a), lm.tour.Pos(c)
+		if i > j {
+			i, j = j, i
+		}
+		lm.tour.Reverse(i+1, j)
+		changed = []int{m.a, m.b, m.c, m.d}
+	case utils.InterRouteExchange:
+		lm.tour.Replace(lm.tour.Pos(m.b), m.d)
+		changed, freed = []int{m.a, m.d, m.c}, m.b
+	case utils.InsertNode:
+		at := lm.tour.Pos(m.a) + 1
+		if lm.direction(m.a, m.b) == -1 {
+			at = lm.tour.Pos(m.b) + 1
+		}
+		lm.tour.Insert(at, m.d)
+		changed = []int{m.a, m.d, m.b}
+	case utils.RemoveNode:
+		lm.tour.Remove(lm.tour.Pos(m.b))
+		changed, freed = []int{m.a, m.c}, m.b
+	}
 
-	return moves
+	// The new edges are the ones between changed nodes
+	for _, node := range changed {
+		next := lm.tour.Next(node)
+		for _, other := range changed {
+			if other == next {
+				added = append(added, [2]int{node, next})
+			}
+		}
+	}
+	for k, e := range added {
+		lm.addTwoEdgesExchangesOf(e[0], e[1], added[:k])
+		lm.addInsertions(e[0], e[1])
+	}
+	for _, node := range changed {
+		lm.addExchangesOf(node)
+	}
+	if freed != -1 {
+		lm.addExchangesWith(freed, changed)
+	}
 }
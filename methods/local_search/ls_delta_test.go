@@ -0,0 +1,105 @@
+package local_search
+
+import (
+	"context"
+	"evolutionary_computation/utils"
+	"math/rand"
+	"testing"
+)
+
+// newTestProblem returns a problem on random points with costs from minCost up to 500.
+func newTestProblem(t *testing.T, seed int64, numNodes, minCost int, selection utils.Selection, weights utils.Weights) *utils.Problem {
+	t.Helper()
+	rng := rand.New(rand.NewSource(seed))
+	nodes := make([]utils.Node, numNodes)
+	for i := range nodes {
+		nodes[i] = utils.Node{ID: i, X: float64(rng.Intn(1000)), Y: float64(rng.Intn(1000)), Cost: minCost + rng.Intn(500-minCost)}
+	}
+	instance := &utils.Instance{Nodes: nodes, Distances: utils.EuclideanDistances(nodes), HasCoordinates: true}
+	problem, err := utils.NewProblem(instance, selection, weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return problem
+}
+
+// newDistinctProblem returns a problem with random edge weights and costs so large that no two
+// moves have the same delta, so that a steepest search has a single path.
+func newDistinctProblem(t *testing.T, seed int64, numNodes, minCost int, selection utils.Selection) *utils.Problem {
+	t.Helper()
+	rng := rand.New(rand.NewSource(seed))
+	nodes := make([]utils.Node, numNodes)
+	distances := make([][]int, numNodes)
+	for i := range nodes {
+		nodes[i] = utils.Node{ID: i, Cost: minCost + rng.Intn(1<<30)}
+		distances[i] = make([]int, numNodes)
+		for j := 0; j < i; j++ {
+			distances[i][j] = rng.Intn(1 << 30)
+			distances[j][i] = distances[i][j]
+		}
+	}
+	problem, err := utils.NewProblem(&utils.Instance{Nodes: nodes, Distances: distances}, selection, utils.UnitWeights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return problem
+}
+
+var lsDeltaTests = []struct {
+	name      string
+	numNodes  int
+	prizes    bool // some costs are negative, so that larger cycles pay off
+	selection utils.Selection
+}{
+	{"half", 40, false, utils.HalfSelection(40)},
+	{"free with prizes", 40, true, utils.Selection{Min: 3, Max: 40}},
+	{"free within a range", 40, true, utils.Selection{Min: 10, Max: 30}},
+	{"smallest cycles", 8, false, utils.Selection{Min: 3, Max: 4}},
+}
+
+func TestLSDeltaLocalOptimum(t *testing.T) {
+	for _, test := range lsDeltaTests {
+		t.Run(test.name, func(t *testing.T) {
+			minCost := 0
+			if test.prizes {
+				minCost = -400
+			}
+			problem := newTestProblem(t, 3, test.numNodes, minCost, test.selection, utils.UnitWeights)
+			for seed := int64(0); seed < 10; seed++ {
+				rng := rand.New(rand.NewSource(seed))
+				solution := LS_Delta(context.Background(), problem, int(seed), rng)
+				if err := utils.ValidateSolution(solution, problem.NumNodes(), problem.Selection); err != nil {
+					t.Fatalf("seed %d: %v", seed, err)
+				}
+
+				// No move of the steepest search with the same neighbourhood improves it
+				tour := utils.NewTour(problem.NumNodes(), solution)
+				for _, move := range generateMoves(tour, "EdgeExchange", problem.Selection, rng) {
+					if d := delta(tour, move, problem); d < 0 {
+						t.Fatalf("seed %d: %v is improved by %+v, delta %d", seed, solution, move, d)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLSDeltaMatchesSteepest(t *testing.T) {
+	for _, test := range lsDeltaTests {
+		t.Run(test.name, func(t *testing.T) {
+			minCost := 0
+			if test.prizes {
+				minCost = -1 << 29
+			}
+			problem := newDistinctProblem(t, 4, test.numNodes, minCost, test.selection)
+			for seed := int64(0); seed < 10; seed++ {
+				// Both start from the same random solution, the seed only breaks ties otherwise
+				lm := LS_Delta(context.Background(), problem, int(seed), rand.New(rand.NewSource(seed)))
+				steepest := RandomSteepestIntraEdge(context.Background(), problem, int(seed), rand.New(rand.NewSource(seed)))
+				if a, b := problem.Fitness(lm), problem.Fitness(steepest); a != b {
+					t.Fatalf("seed %d: LS_Delta stops at %d in %v, the steepest search at %d in %v", seed, a, lm, b, steepest)
+				}
+			}
+		})
+	}
+}
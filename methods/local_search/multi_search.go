@@ -1,85 +1,103 @@
 package local_search
 
 import (
+	"context"
+	"evolutionary_computation/solver"
 	"evolutionary_computation/utils"
 	"math/rand"
 	"time"
 )
 
-func MultiLocalSearch(costMatrix [][]int, pointless_value int) []int {
+func init() {
+	solver.Register(solver.New("LS_multi", []solver.Param{
+		{Name: "starts", Type: solver.Int, Default: 200, Min: 1, Max: 1000000, Usage: "number of local searches started from random solutions"},
+	}, MultiLocalSearch))
+	solver.Register(solver.New("LS_iterative", []solver.Param{
+		{Name: "percentage", Type: solver.Float, Default: 0.3, Min: 0, Max: 1, Usage: "fraction of the solution replaced by the perturbation"},
+	}, IterativeLocalSearch))
+}
+
+// MultiLocalSearch runs the steepest local search from random solutions and keeps the best one.
+func MultiLocalSearch(ctx context.Context, run solver.Run) solver.Result {
+	problem := run.Problem
+	starts := run.Params.Int("starts")
 	var bestFitness int
 	var bestSolution []int
 
-	for i := 0; i < 200; i++ {
-		startNode := i % len(costMatrix)
+	meter := run.Start(ctx, 0) // only traces the starts, their number is the budget
+	done := 0
+	for i := 0; i < starts && (i == 0 || ctx.Err() == nil); i++ {
+		startNode := i % problem.NumNodes()
 
-		solution := RandomSteepestIntraEdge(costMatrix, startNode)
-		fitness := utils.Fitness(solution, costMatrix)
+		solution := RandomSteepestIntraEdge(ctx, problem, startNode, run.Rng)
+		fitness := problem.Fitness(solution)
 
 		if i == 0 || fitness < bestFitness {
 			bestFitness = fitness
 			bestSolution = solution
 		}
+		meter.Observe(fitness, bestFitness)
+		done++
 	}
 
-	return bestSolution
+	return solver.Result{Solution: bestSolution, Fitness: bestFitness, Iterations: done}
 }
 
-func IterativeLocalSearch(costMatrix [][]int, pointless_value int) []int {
+// IterativeLocalSearch perturbs the best solution found so far and improves it with the steepest local search.
+func IterativeLocalSearch(ctx context.Context, run solver.Run) solver.Result {
+	problem := run.Problem
 	var bestFitness int
 	var bestSolution []int
 	var callCount int
 	var solution []int
 
-	var percentage float64 = 0.3
-
-	startTime := time.Now()
+	percentage := run.Params.Float("percentage")
 
-	//TODO: change the time to average from MultiLocalSearch
-	for time.Since(startTime) < 30*time.Second {
-		startNode := callCount % len(costMatrix)
+	meter := run.Start(ctx, 30*time.Second)
+	for callCount == 0 || !meter.Exhausted() { // at least one solution, even on a spent budget
+		startNode := callCount % problem.NumNodes()
 
 		if callCount == 0 {
-			solution = RandomSteepestIntraEdge(costMatrix, startNode)
+			solution = RandomSteepestIntraEdge(ctx, problem, startNode, run.Rng)
 		} else {
 			bestSolutionCopy := make([]int, len(bestSolution))
 			copy(bestSolutionCopy, bestSolution)
 
-			permutatedSolution := PermuteSolution(bestSolutionCopy, percentage)
+			permutatedSolution := PermuteSolution(bestSolutionCopy, percentage, problem.NumNodes(), run.Rng)
 
-			solution = SteepestIntraEdgeFromSolution(permutatedSolution, costMatrix, startNode)
+			solution = SteepestIntraEdgeFromSolution(ctx, permutatedSolution, problem, startNode, run.Rng)
 		}
 
 		callCount++
-		fitness := utils.Fitness(solution, costMatrix)
+		fitness := problem.Fitness(solution)
 
 		if callCount == 1 || fitness < bestFitness {
 			bestFitness = fitness
 			bestSolution = solution
 		}
+		meter.Observe(fitness, bestFitness)
 	}
-	// TODO: add callCount to results dict
-	println("Number of RandomSteepestIntraEdge calls:", callCount)
-	return bestSolution
+	return solver.Result{Solution: bestSolution, Fitness: bestFitness, Iterations: callCount}
 }
 
-func PermuteSolution(solution []int, percentage float64) []int {
+// PermuteSolution replaces a segment covering the given percentage of the solution with random
+// nodes from outside of the solution.
+func PermuteSolution(solution []int, percentage float64, numNodes int, rng *rand.Rand) []int {
 	// take 2 random indexes wchich will cover n percent of the solution
 	m := len(solution)
-	start := rand.Intn(m)
+	start := rng.Intn(m)
 	end := start + int(percentage*float64(m))
+	if m >= numNodes {
+		return solution // no nodes outside of the solution to choose from
+	}
 
 	// permute the solution
+	tour := utils.NewTour(numNodes, solution)
 	for i := start; i < end; i++ {
 		//change the node to random value outside of the solution
-
-		new_node := rand.Intn(m)
-		for utils.Contains(solution, new_node) {
-			new_node = rand.Intn(m)
-		}
-		index := i % m
-		solution[index] = new_node
+		unselected := tour.Unselected()
+		tour.Replace(i%m, unselected[rng.Intn(len(unselected))])
 	}
 
-	return solution
+	return tour.Solution()
 }
@@ -0,0 +1,84 @@
+package local_search
+
+import (
+	"context"
+	"evolutionary_computation/pareto"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"time"
+)
+
+// ParetoParams are the parameters of ParetoLocalSearch.
+var ParetoParams = []solver.Param{
+	{Name: "weights", Type: solver.Int, Default: 20, Min: 2, Max: 10000, Usage: "number of weighted sums of length and cost whose local optima start the search"},
+}
+
+// ParetoLocalSearch approximates the trade-off between the length of the cycle and the cost of its nodes.
+// It starts from the local optima of evenly spread weighted sums of both objectives, then keeps
+// searching the 2-opt and inter-route exchange neighbourhoods of every non-dominated solution it finds
+// until all of them were searched or the time runs out.
+// It returns the archive of non-dominated solutions and the number of neighbours evaluated.
+func ParetoLocalSearch(ctx context.Context, run solver.Run) (*pareto.Archive, int) {
+	problem := run.Problem
+	lengthOnly := problem.WithWeights(utils.Weights{Alpha: 1, Beta: 0})
+	numWeights := run.Params.Int("weights")
+	meter := run.Start(ctx, 30*time.Second)
+
+	var archive pareto.Archive
+	for i := 0; i < numWeights; i++ {
+		weighted := problem.WithWeights(utils.Weights{Alpha: i, Beta: numWeights - 1 - i})
+		solution := RandomSteepestIntraEdge(ctx, weighted, (run.StartNode+i)%problem.NumNodes(), run.Rng)
+		archive.Add(pareto.Point{Length: problem.Length(solution), Cost: problem.NodeCost(solution), Solution: solution})
+	}
+
+	evaluations := 0
+	for !meter.Exhausted() {
+		point, ok := archive.NextUnexplored()
+		if !ok {
+			break
+		}
+		n := exploreNeighbourhood(&archive, point, problem, lengthOnly)
+		evaluations += n
+	}
+	return &archive, evaluations
+}
+
+// exploreNeighbourhood adds the neighbours of the point that no solution of the archive is as good as.
+func exploreNeighbourhood(archive *pareto.Archive, point pareto.Point, problem, lengthOnly *utils.Problem) int {
+	tour := utils.NewTour(problem.NumNodes(), point.Solution)
+	n := tour.Len()
+	evaluations := 0
+
+	// Intra-route: two-edges exchange, the node costs stay the same
+	if n >= 4 {
+		for i := 0; i < n; i++ {
+			for j := i + 2; j < n; j++ {
+				if i == 0 && j == n-1 {
+					continue // the edges are adjacent
+				}
+				evaluations++
+				length := point.Length + deltaTwoEdgesExchange(tour, i, j, lengthOnly)
+				if archive.Accepts(length, point.Cost) {
+					neighbour := tour.Clone()
+					neighbour.Reverse(i+1, j)
+					archive.Add(pareto.Point{Length: length, Cost: point.Cost, Solution: neighbour.Order()})
+				}
+			}
+		}
+	}
+
+	// Inter-route: exchange between selected and unselected nodes
+	for i := 0; i < n; i++ {
+		for _, unselected := range tour.Unselected() {
+			evaluations++
+			length := point.Length + deltaInterRouteExchange(tour, i, unselected, lengthOnly)
+			cost := point.Cost + problem.Costs[unselected] - problem.Costs[tour.At(i)]
+			if archive.Accepts(length, cost) {
+				neighbour := tour.Solution()
+				neighbour[i] = unselected
+				archive.Add(pareto.Point{Length: length, Cost: cost, Solution: neighbour})
+			}
+		}
+	}
+	return evaluations
+}
@@ -1,31 +1,29 @@
 package local_search
 
 import (
+	"context"
 	"evolutionary_computation/methods"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"math/rand"
 )
 
-func RandomGreedyIntraEdge(distanceMatrix [][]int, startNode int) []int {
+func init() {
+	solver.Register(solver.NewMethod("LS_random_greedy_intraedge", func(ctx context.Context, run solver.Run) []int {
+		return RandomGreedyIntraEdge(ctx, run.Problem, run.StartNode, run.Rng)
+	}))
+}
+
+func RandomGreedyIntraEdge(ctx context.Context, problem *utils.Problem, startNode int, rng *rand.Rand) []int {
+	problem.Counters().LocalSearch()
 	// Run random function to get the initial solution
-	selectedIDs := methods.RandomSolution(distanceMatrix, startNode)
-	visted := make(map[int]bool)
-	// Make visited map
-	for _, id := range selectedIDs {
-		visted[id] = true
-	}
-	// Make unselected list out of all nodes form matrix without selected nodes
-	m := len(distanceMatrix)
-	unselected := make([]int, 0, m)
-	for i := 0; i < m; i++ {
-		if _, ok := visted[i]; !ok {
-			unselected = append(unselected, i)
-		}
-	}
+	selectedIDs := methods.RandomSolution(problem, startNode, rng)
+	tour := utils.NewTour(problem.NumNodes(), selectedIDs)
 
 	// Run local search function as long as there is improvement
-	solution, improved := GreedyMove(selectedIDs, visted, unselected, distanceMatrix, "EdgeExchange")
-	for improved {
-		solution, improved = GreedyMove(solution, visted, unselected, distanceMatrix, "EdgeExchange")
-
+	improved := GreedyMove(tour, problem, "EdgeExchange", rng)
+	for improved && ctx.Err() == nil {
+		improved = GreedyMove(tour, problem, "EdgeExchange", rng)
 	}
-	return solution
+	return tour.Solution()
 }
@@ -1,31 +1,29 @@
 package local_search
 
 import (
+	"context"
 	"evolutionary_computation/methods"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"math/rand"
 )
 
-func RandomGreedyIntraNode(distanceMatrix [][]int, startNode int) []int {
+func init() {
+	solver.Register(solver.NewMethod("LS_random_greedy_intranode", func(ctx context.Context, run solver.Run) []int {
+		return RandomGreedyIntraNode(ctx, run.Problem, run.StartNode, run.Rng)
+	}))
+}
+
+func RandomGreedyIntraNode(ctx context.Context, problem *utils.Problem, startNode int, rng *rand.Rand) []int {
+	problem.Counters().LocalSearch()
 	// Run random function to get the initial solution
-	selectedIDs := methods.RandomSolution(distanceMatrix, startNode)
-	visted := make(map[int]bool)
-	// Make visited map
-	for _, id := range selectedIDs {
-		visted[id] = true
-	}
-	// Make unselected list out of all nodes form matrix without selected nodes
-	m := len(distanceMatrix)
-	unselected := make([]int, 0, m)
-	for i := 0; i < m; i++ {
-		if _, ok := visted[i]; !ok {
-			unselected = append(unselected, i)
-		}
-	}
+	selectedIDs := methods.RandomSolution(problem, startNode, rng)
+	tour := utils.NewTour(problem.NumNodes(), selectedIDs)
 
 	// Run local search function as long as there is improvement
-	solution, improved := GreedyMove(selectedIDs, visted, unselected, distanceMatrix, "NodeExchange")
-	for improved {
-		solution, improved = GreedyMove(solution, visted, unselected, distanceMatrix, "NodeExchange")
-
+	improved := GreedyMove(tour, problem, "NodeExchange", rng)
+	for improved && ctx.Err() == nil {
+		improved = GreedyMove(tour, problem, "NodeExchange", rng)
 	}
-	return solution
+	return tour.Solution()
 }
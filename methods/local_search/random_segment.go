@@ -0,0 +1,51 @@
+package local_search
+
+import (
+	"context"
+	"evolutionary_computation/methods"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"math/rand"
+)
+
+func init() {
+	for suffix, intraMoveType := range map[string]string{"oropt": "OrOpt", "or2opt": "Or2Opt", "3opt": "ThreeOpt"} {
+		solver.Register(solver.NewMethod("LS_random_greedy_"+suffix, func(ctx context.Context, run solver.Run) []int {
+			return RandomGreedy(ctx, run.Problem, run.StartNode, intraMoveType, run.Rng)
+		}))
+		solver.Register(solver.NewMethod("LS_random_steepest_"+suffix, func(ctx context.Context, run solver.Run) []int {
+			return RandomSteepest(ctx, run.Problem, run.StartNode, intraMoveType, run.Rng)
+		}))
+	}
+}
+
+// RandomGreedy is the greedy local search from a random solution with the given intra-route moves:
+// "OrOpt" relocates segments of up to MaxSegment nodes, reversed or not, "Or2Opt" also
+// exchanges two edges as 2-opt does, and "ThreeOpt" exchanges two consecutive segments of any
+// length, the 3-opt moves that reverse no segment.
+func RandomGreedy(ctx context.Context, problem *utils.Problem, startNode int, intraMoveType string, rng *rand.Rand) []int {
+	problem.Counters().LocalSearch()
+	// Run random function to get the initial solution
+	tour := utils.NewTour(problem.NumNodes(), methods.RandomSolution(problem, startNode, rng))
+
+	// Run local search function as long as there is improvement
+	improved := GreedyMove(tour, problem, intraMoveType, rng)
+	for improved && ctx.Err() == nil {
+		improved = GreedyMove(tour, problem, intraMoveType, rng)
+	}
+	return tour.Solution()
+}
+
+// RandomSteepest is the steepest local search from a random solution, see RandomGreedy for the moves.
+func RandomSteepest(ctx context.Context, problem *utils.Problem, startNode int, intraMoveType string, rng *rand.Rand) []int {
+	problem.Counters().LocalSearch()
+	// Run random function to get the initial solution
+	tour := utils.NewTour(problem.NumNodes(), methods.RandomSolution(problem, startNode, rng))
+
+	// Run local search function as long as there is improvement
+	improved := SteepestMove(tour, problem, intraMoveType, rng)
+	for improved && ctx.Err() == nil {
+		improved = SteepestMove(tour, problem, intraMoveType, rng)
+	}
+	return tour.Solution()
+}
@@ -1,56 +1,41 @@
 package local_search
 
 import (
+	"context"
 	"evolutionary_computation/methods"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+	"math/rand"
 )
 
-func RandomSteepestIntraEdge(distanceMatrix [][]int, startNode int) []int {
+func init() {
+	solver.Register(solver.NewMethod("LS_random_steepest_intraedge", func(ctx context.Context, run solver.Run) []int {
+		return RandomSteepestIntraEdge(ctx, run.Problem, run.StartNode, run.Rng)
+	}))
+}
+
+func RandomSteepestIntraEdge(ctx context.Context, problem *utils.Problem, startNode int, rng *rand.Rand) []int {
+	problem.Counters().LocalSearch()
 	// Run random function to get the initial solution
-	initialSolution := methods.RandomSolution(distanceMatrix, startNode)
-	visted := make(map[int]bool)
-	// Make visited map
-	for _, id := range initialSolution {
-		visted[id] = true
-	}
-	// Make unselected list out of all nodes form matrix without selected nodes
-	m := len(distanceMatrix)
-	unselected := make([]int, 0, m)
-	for i := 0; i < m; i++ {
-		if _, ok := visted[i]; !ok {
-			unselected = append(unselected, i)
-		}
-	}
+	initialSolution := methods.RandomSolution(problem, startNode, rng)
+	tour := utils.NewTour(problem.NumNodes(), initialSolution)
 
 	// Run local search function as long as there is improvement
-	solution, improved := SteepestMove(initialSolution, visted, unselected, distanceMatrix, "EdgeExchange")
-	for improved {
-		solution, improved = SteepestMove(solution, visted, unselected, distanceMatrix, "EdgeExchange")
-
+	improved := SteepestMove(tour, problem, "EdgeExchange", rng)
+	for improved && ctx.Err() == nil {
+		improved = SteepestMove(tour, problem, "EdgeExchange", rng)
 	}
-	return solution
+	return tour.Solution()
 }
 
-
-func SteepestIntraEdgeFromSolution(initialSolution []int, distanceMatrix [][]int, startNode int) []int {
-	visted := make(map[int]bool)
-	// Make visited map
-	for _, id := range initialSolution {
-		visted[id] = true
-	}
-	// Make unselected list out of all nodes form matrix without selected nodes
-	m := len(distanceMatrix)
-	unselected := make([]int, 0, m)
-	for i := 0; i < m; i++ {
-		if _, ok := visted[i]; !ok {
-			unselected = append(unselected, i)
-		}
-	}
+func SteepestIntraEdgeFromSolution(ctx context.Context, initialSolution []int, problem *utils.Problem, startNode int, rng *rand.Rand) []int {
+	problem.Counters().LocalSearch()
+	tour := utils.NewTour(problem.NumNodes(), initialSolution)
 
 	// Run local search function as long as there is improvement
-	solution, improved := SteepestMove(initialSolution, visted, unselected, distanceMatrix, "EdgeExchange")
-	for improved {
-		solution, improved = SteepestMove(solution, visted, unselected, distanceMatrix, "EdgeExchange")
-
+	improved := SteepestMove(tour, problem, "EdgeExchange", rng)
+	for improved && ctx.Err() == nil {
+		improved = SteepestMove(tour, problem, "EdgeExchange", rng)
 	}
-	return solution
+	return tour.Solution()
 }
@@ -1,24 +1,34 @@
 package methods
 
-import "evolutionary_computation/utils"
+import (
+	"context"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+)
+
+func init() {
+	solver.Register(solver.NewMethod("nearest_neighbour_end_only", func(ctx context.Context, run solver.Run) []int {
+		return NearestNeighborEndOnly(run.Problem, run.StartNode)
+	}))
+}
 
 // NearestNeighborEndOnly generates a solution using the nearest neighbor heuristic, starting from a random node.
 // The algorithm selects the nearest neighbor of the last node in the solution until half of the nodes are selected.
-func NearestNeighborEndOnly(distanceMatrix [][]int, startNode int) []int {
-	numNodes, numToSelect, selectedIDs, visited := utils.GetInitialState(distanceMatrix, startNode)
+func NearestNeighborEndOnly(problem *utils.Problem, startNode int) []int {
+	tour := utils.NewTour(problem.NumNodes(), []int{startNode})
 
 	// Continue adding the nearest neighbor of last node until half of the nodes are selected
-	for len(selectedIDs) < numToSelect {
+	for tour.Len() < problem.Min {
 		// Get the last node in the solution
-		lastNode := selectedIDs[len(selectedIDs)-1]
+		lastNode := tour.At(-1)
 
 		// Find the nearest neighbor that has not been visited
 		nearestNeighbor := -1
 		minDistance := -1
 
-		for i := 0; i < numNodes; i++ {
-			if !visited[i] {
-				distance := distanceMatrix[lastNode][i]
+		for i := 0; i < tour.NumNodes(); i++ {
+			if !tour.IsSelected(i) {
+				distance := problem.Distance(lastNode, i) + problem.Cost(i)
 				if minDistance == -1 || distance < minDistance {
 					minDistance = distance
 					nearestNeighbor = i
@@ -27,10 +37,9 @@ func NearestNeighborEndOnly(distanceMatrix [][]int, startNode int) []int {
 		}
 
 		// Add the nearest neighbor to the solution
-		selectedIDs = append(selectedIDs, nearestNeighbor)
-		visited[nearestNeighbor] = true
+		tour.Insert(tour.Len(), nearestNeighbor)
 	}
 
 	// Construct a cycle with the selected nodes' IDs
-	return selectedIDs // Return list of visited node IDs in the order of the cycle
+	return problem.Extend(tour.Solution()) // Return list of visited node IDs in the order of the cycle
 }
@@ -1,35 +1,61 @@
 package methods
 
-import "evolutionary_computation/utils"
+import (
+	"context"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
+)
+
+func init() {
+	solver.Register(solver.NewMethod("nearest_neighbour_flexible", func(ctx context.Context, run solver.Run) []int {
+		return NearestNeighborFlexible(run.Problem, run.StartNode)
+	}))
+}
 
 // NearestNeighborFlexible generates a solution using the nearest neighbor heuristic, starting from a random node.
 // The algorithm selects the nearest neighbor of any node in the solution until half of the nodes are selected.
-func NearestNeighborFlexible(distanceMatrix [][]int, startNode int) []int {
-	numNodes, numToSelect, solution, visited := utils.GetInitialState(distanceMatrix, startNode)
+func NearestNeighborFlexible(problem *utils.Problem, startNode int) []int {
+	return NearestNeighborFlexibleFromSolution(problem, []int{startNode})
+}
+
+// NearestNeighborFlexibleFromSolution repairs a partial solution, as left by the destroy operators,
+// with the nearest neighbor heuristic. An empty solution is started from the cheapest node.
+func NearestNeighborFlexibleFromSolution(problem *utils.Problem, solution []int) []int {
+	return NearestNeighborFlexibleToSize(problem, solution, problem.Min)
+}
 
-	// Continue adding the nearest neighbor until half of the nodes are selected
-	for len(solution) < numToSelect {
+// NearestNeighborFlexibleToSize repairs a partial solution like NearestNeighborFlexibleFromSolution,
+// but until it selects size nodes, kept within the selection, before extending it. It lets a method
+// with a free selection choose the size of the repaired solution.
+func NearestNeighborFlexibleToSize(problem *utils.Problem, solution []int, size int) []int {
+	if len(solution) == 0 {
+		solution = append(solution, cheapestNode(problem))
+	}
+	tour := utils.NewTour(problem.NumNodes(), solution)
+
+	// Continue adding the nearest neighbor until the solution has the requested size
+	size = min(max(size, problem.Min), problem.Max)
+	for tour.Len() < size {
+		solution := tour.Order()
 		// Find the nearest neighbor that has not been visited
 		nearestNeighbor := -1
 		minInsertionCost := int(^uint(0) >> 1) // Max int value
 		insertPosition := -1
 
-		for i := 0; i < numNodes; i++ {
-			if !visited[i] {
+		for i := 0; i < tour.NumNodes(); i++ {
+			if !tour.IsSelected(i) {
 				// Calculate insertion costs for each position in the solution
 				for j := 0; j <= len(solution); j++ {
 					var cost int
 					if j == 0 {
 						// Insert at the beginning
-						cost = distanceMatrix[solution[0]][i]
+						cost = problem.Distance(solution[0], i) + problem.Cost(i)
 					} else if j == len(solution) {
 						// Insert at the end
-						cost = distanceMatrix[solution[len(solution)-1]][i]
+						cost = problem.Distance(solution[len(solution)-1], i) + problem.Cost(i)
 					} else {
 						// Insert between solution[j-1] and solution[j]
-						cost = distanceMatrix[solution[j-1]][i] +
-							distanceMatrix[i][solution[j]] -
-							distanceMatrix[solution[j-1]][solution[j]]
+						cost = problem.InsertionCost(solution[j-1], i, solution[j])
 					}
 
 					// Update nearest neighbor if a lower cost is found
@@ -43,75 +69,20 @@ func NearestNeighborFlexible(distanceMatrix [][]int, startNode int) []int {
 		}
 
 		// Insert the nearest neighbor at the best position found
-		if insertPosition == len(solution) {
-			// If the insert position is at the end, simply append the element
-			solution = append(solution, nearestNeighbor)
-		} else {
-			// Otherwise, insert the element at the correct position
-			solution = append(solution[:insertPosition+1], solution[insertPosition:]...) // Resize the slice
-			solution[insertPosition] = nearestNeighbor
-		}
-
-		// Mark the nearest neighbor as visited
-		visited[nearestNeighbor] = true
+		tour.Insert(insertPosition, nearestNeighbor)
 	}
 
 	// Return list of visited node IDs in the order of the cycle
-	return solution
+	return problem.Extend(tour.Solution())
 }
 
-func NearestNeighborFlexibleFromSolution(distanceMatrix [][]int, solution []int) []int {
-	numNodes, numToSelect, visited := utils.GetSuggestedState(distanceMatrix, solution)
-
-	// Continue adding the nearest neighbor until half of the nodes are selected
-	for len(solution) < numToSelect {
-		// Find the nearest neighbor that has not been visited
-		nearestNeighbor := -1
-		minInsertionCost := int(^uint(0) >> 1) // Max int value
-		insertPosition := -1
-
-		for i := 0; i < numNodes; i++ {
-			if !visited[i] {
-				// Calculate insertion costs for each position in the solution
-				for j := 0; j <= len(solution); j++ {
-					var cost int
-					if j == 0 {
-						// Insert at the beginning
-						cost = distanceMatrix[solution[0]][i]
-					} else if j == len(solution) {
-						// Insert at the end
-						cost = distanceMatrix[solution[len(solution)-1]][i]
-					} else {
-						// Insert between solution[j-1] and solution[j]
-						cost = distanceMatrix[solution[j-1]][i] +
-							distanceMatrix[i][solution[j]] -
-							distanceMatrix[solution[j-1]][solution[j]]
-					}
-
-					// Update nearest neighbor if a lower cost is found
-					if cost < minInsertionCost {
-						minInsertionCost = cost
-						nearestNeighbor = i
-						insertPosition = j // Store the position for insertion
-					}
-				}
-			}
-		}
-
-		// Insert the nearest neighbor at the best position found
-		if insertPosition == len(solution) {
-			// If the insert position is at the end, simply append the element
-			solution = append(solution, nearestNeighbor)
-		} else {
-			// Otherwise, insert the element at the correct position
-			solution = append(solution[:insertPosition+1], solution[insertPosition:]...) // Resize the slice
-			solution[insertPosition] = nearestNeighbor
+// cheapestNode returns the node with the lowest cost.
+func cheapestNode(problem *utils.Problem) int {
+	best := 0
+	for i := 0; i < problem.NumNodes(); i++ {
+		if problem.Cost(i) < problem.Cost(best) {
+			best = i
 		}
-
-		// Mark the nearest neighbor as visited
-		visited[nearestNeighbor] = true
 	}
-
-	// Return list of visited node IDs in the order of the cycle
-	return solution
+	return best
 }
@@ -1,16 +1,29 @@
 package methods
 
 import (
+	"context"
+	"evolutionary_computation/solver"
+	"evolutionary_computation/utils"
 	"math/rand"
 )
 
+func init() {
+	solver.Register(solver.NewMethod("random", func(ctx context.Context, run solver.Run) []int {
+		return RandomSolution(run.Problem, run.StartNode, run.Rng)
+	}))
+}
+
 // RandomSolution generates a random solution and returns a list of node IDs in the selected order.
-func RandomSolution(distanceMatrix [][]int, startNode int) []int {
-	numNodes := len(distanceMatrix)
-	numToSelect := (numNodes + 1) / 2 // Rounds up if odd
+// When the method chooses the number of nodes, it is drawn uniformly from the selection range.
+func RandomSolution(problem *utils.Problem, startNode int, rng *rand.Rand) []int {
+	numNodes := problem.NumNodes()
+	numToSelect := problem.Min
+	if !problem.Fixed() {
+		numToSelect += rng.Intn(problem.Max - problem.Min + 1)
+	}
 	selectedIDs := make([]int, 0, numToSelect)
 
-	perm := rand.Perm(numNodes)
+	perm := rng.Perm(numNodes)
 	for i := 0; i < numToSelect; i++ {
 		selectedIDs = append(selectedIDs, perm[i])
 	}
@@ -0,0 +1,69 @@
+package pareto
+
+import "sort"
+
+// Point is a solution with its two objectives, the length of the cycle and the cost of its nodes.
+type Point struct {
+	Length   int   `json:"length"`
+	Cost     int   `json:"cost"`
+	Solution []int `json:"solution,omitempty"`
+
+	explored bool // the neighbourhood of the solution was searched
+}
+
+// Dominates tells whether p is at least as good as q in both objectives and better in one.
+func (p Point) Dominates(q Point) bool {
+	return p.Length <= q.Length && p.Cost <= q.Cost && (p.Length < q.Length || p.Cost < q.Cost)
+}
+
+// Archive is a set of mutually non-dominated points, sorted by length.
+// Along the archive the length grows and the cost falls.
+type Archive struct {
+	points []Point
+}
+
+// Points returns the points of the archive sorted by length.
+func (a *Archive) Points() []Point {
+	return a.points
+}
+
+// Len returns the number of points in the archive.
+func (a *Archive) Len() int {
+	return len(a.points)
+}
+
+// Accepts tells whether a point with the given objectives would enter the archive,
+// that is whether no point of the archive is as good as it in both objectives.
+func (a *Archive) Accepts(length, cost int) bool {
+	i := sort.Search(len(a.points), func(i int) bool { return a.points[i].Length > length })
+	// The point before i has the lowest cost among the points not longer than the new one
+	return i == 0 || a.points[i-1].Cost > cost
+}
+
+// Add inserts the point unless a point of the archive is as good as it in both objectives,
+// and removes the points it dominates. It tells whether the point was inserted.
+func (a *Archive) Add(p Point) bool {
+	if !a.Accepts(p.Length, p.Cost) {
+		return false
+	}
+	i := sort.Search(len(a.points), func(i int) bool { return a.points[i].Length >= p.Length })
+	// The dominated points follow i, as long as their cost isn't lower
+	j := i
+	for j < len(a.points) && a.points[j].Cost >= p.Cost {
+		j++
+	}
+	a.points = append(a.points[:i], append([]Point{p}, a.points[j:]...)...)
+	return true
+}
+
+// NextUnexplored returns a point of the archive whose neighbourhood wasn't searched yet and
+// marks it as explored, for Pareto local search. It returns false when all points were explored.
+func (a *Archive) NextUnexplored() (Point, bool) {
+	for i := range a.points {
+		if !a.points[i].explored {
+			a.points[i].explored = true
+			return a.points[i], true
+		}
+	}
+	return Point{}, false
+}
@@ -0,0 +1,127 @@
+package pareto
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// checkArchive fails the test unless the points of the archive are sorted by length with the cost
+// falling along them, and none dominates or equals another.
+func checkArchive(t *testing.T, archive *Archive) {
+	t.Helper()
+	points := archive.Points()
+	for k := 1; k < len(points); k++ {
+		if points[k-1].Length >= points[k].Length || points[k-1].Cost <= points[k].Cost {
+			t.Fatalf("points %d and %d of %v are out of order", k-1, k, points)
+		}
+	}
+}
+
+// bruteForceFront returns the objectives of the points that no other point dominates.
+func bruteForceFront(points []Point) map[[2]int]bool {
+	front := make(map[[2]int]bool)
+	for _, p := range points {
+		dominated := false
+		for _, q := range points {
+			dominated = dominated || q.Dominates(p)
+		}
+		if !dominated {
+			front[[2]int{p.Length, p.Cost}] = true
+		}
+	}
+	return front
+}
+
+func TestDominates(t *testing.T) {
+	tests := []struct {
+		name      string
+		p, q      Point
+		dominates bool
+	}{
+		{"better in both", Point{Length: 1, Cost: 1}, Point{Length: 2, Cost: 2}, true},
+		{"better in length", Point{Length: 1, Cost: 2}, Point{Length: 2, Cost: 2}, true},
+		{"better in cost", Point{Length: 2, Cost: 1}, Point{Length: 2, Cost: 2}, true},
+		{"equal", Point{Length: 2, Cost: 2}, Point{Length: 2, Cost: 2}, false},
+		{"trade-off", Point{Length: 1, Cost: 3}, Point{Length: 2, Cost: 2}, false},
+		{"worse", Point{Length: 3, Cost: 3}, Point{Length: 2, Cost: 2}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if dominates := test.p.Dominates(test.q); dominates != test.dominates {
+				t.Fatalf("%+v.Dominates(%+v) = %v, expected %v", test.p, test.q, dominates, test.dominates)
+			}
+		})
+	}
+}
+
+func TestArchive(t *testing.T) {
+	// A small range of objectives gives equal and dominated points often
+	for _, span := range []int{5, 20, 1000} {
+		rng := rand.New(rand.NewSource(int64(span)))
+		var archive Archive
+		var added []Point
+		for step := 0; step < 500; step++ {
+			p := Point{Length: rng.Intn(span), Cost: rng.Intn(span)}
+			present := archiveHas(&archive, p)
+			accepts := archive.Accepts(p.Length, p.Cost)
+			inserted := archive.Add(p)
+			if accepts != inserted {
+				t.Fatalf("span %d, step %d: Accepts(%+v) = %v, Add = %v", span, step, p, accepts, inserted)
+			}
+			added = append(added, p)
+			checkArchive(t, &archive)
+
+			front := bruteForceFront(added)
+			if archive.Len() != len(front) {
+				t.Fatalf("span %d, step %d: %d points in the archive, %d non-dominated", span, step, archive.Len(), len(front))
+			}
+			for _, q := range archive.Points() {
+				if !front[[2]int{q.Length, q.Cost}] {
+					t.Fatalf("span %d, step %d: %+v is in the archive, but dominated", span, step, q)
+				}
+			}
+			// A point equal to one of the archive is not inserted again
+			if expected := front[[2]int{p.Length, p.Cost}] && !present; inserted != expected {
+				t.Fatalf("span %d, step %d: Add(%+v) = %v, expected %v", span, step, p, inserted, expected)
+			}
+		}
+	}
+}
+
+func archiveHas(archive *Archive, p Point) bool {
+	for _, q := range archive.Points() {
+		if q.Length == p.Length && q.Cost == p.Cost {
+			return true
+		}
+	}
+	return false
+}
+
+func TestNextUnexplored(t *testing.T) {
+	var archive Archive
+	for _, p := range []Point{{Length: 1, Cost: 5}, {Length: 3, Cost: 2}, {Length: 2, Cost: 4}} {
+		archive.Add(p)
+	}
+	seen := make(map[int]bool)
+	for {
+		p, ok := archive.NextUnexplored()
+		if !ok {
+			break
+		}
+		if seen[p.Length] {
+			t.Fatalf("%+v returned twice", p)
+		}
+		seen[p.Length] = true
+	}
+	if len(seen) != 3 {
+		t.Fatalf("%d points explored, expected 3", len(seen))
+	}
+	// A new point is unexplored, the points it replaces are gone
+	archive.Add(Point{Length: 1, Cost: 1})
+	if p, ok := archive.NextUnexplored(); !ok || p.Length != 1 || p.Cost != 1 {
+		t.Fatalf("NextUnexplored() = %+v, %v after adding a point dominating all", p, ok)
+	}
+	if archive.Len() != 1 {
+		t.Fatalf("%d points left, expected 1", archive.Len())
+	}
+}
@@ -0,0 +1,122 @@
+package pareto
+
+import (
+	"encoding/json"
+	"evolutionary_computation/experiment"
+	"evolutionary_computation/solver"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+)
+
+// Front is the outcome of a bi-objective run: the non-dominated points with their quality
+// indicators, normalized with the bounds of the front itself.
+type Front struct {
+	Method      string               `json:"method"`
+	Params      solver.Params        `json:"params"`
+	Seed        int64                `json:"seed"`
+	Points      []Point              `json:"points"`
+	Bounds      Bounds               `json:"bounds"`
+	Hypervolume float64              `json:"hypervolume"`
+	Spacing     float64              `json:"spacing"`
+	Spread      float64              `json:"spread"`
+	Evaluations int                  `json:"evaluations"`           // neighbours evaluated by the search
+	Interrupted bool                 `json:"interrupted,omitempty"` // the search was stopped before its time limit
+	Metadata    *experiment.Metadata `json:"metadata,omitempty"`
+}
+
+// NewFront computes the indicators of the points of the archive.
+func NewFront(method string, params solver.Params, seed int64, archive *Archive) Front {
+	points := archive.Points()
+	bounds := BoundsOf(points)
+	return Front{
+		Method:      method,
+		Params:      params,
+		Seed:        seed,
+		Points:      points,
+		Bounds:      bounds,
+		Hypervolume: Hypervolume(points, bounds),
+		Spacing:     Spacing(points, bounds),
+		Spread:      Spread(points, bounds),
+	}
+}
+
+// LoadFront reads a front written by the pareto command.
+func LoadFront(filename string) (Front, error) {
+	var front Front
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		return front, err
+	}
+	if err := json.Unmarshal(bytes, &front); err != nil {
+		return front, fmt.Errorf("%s: %w", filename, err)
+	}
+	return front, nil
+}
+
+// Instance returns the name of the instance the front was computed on, if it is known.
+func (f Front) Instance() string {
+	if f.Metadata == nil || f.Metadata.Instance == "" {
+		return ""
+	}
+	return experiment.InstanceName(f.Metadata.Instance)
+}
+
+// Comparison holds the indicators of two fronts. Fronts of the same instance are normalized
+// with common bounds and compared point by point, fronts of different instances, like TSPA
+// and TSPB, are each normalized with their own bounds so only the shape of the fronts is compared.
+type Comparison struct {
+	Names       [2]string
+	Sizes       [2]int
+	Hypervolume [2]float64
+	Spacing     [2]float64
+	Spread      [2]float64
+	SameBounds  bool
+	IGD         [2]float64 // to the non-dominated points of both fronts, only with SameBounds
+	Coverage    [2]float64 // Coverage[0] is the fraction of the second front covered by the first, only with SameBounds
+}
+
+// Compare computes the indicators of both fronts.
+func Compare(a, b Front) Comparison {
+	c := Comparison{
+		Names:      [2]string{a.Method + " on " + a.Instance(), b.Method + " on " + b.Instance()},
+		Sizes:      [2]int{len(a.Points), len(b.Points)},
+		SameBounds: a.Instance() == b.Instance(),
+	}
+
+	bounds := [2]Bounds{BoundsOf(a.Points), BoundsOf(b.Points)}
+	if c.SameBounds {
+		common := BoundsOf(a.Points, b.Points)
+		bounds = [2]Bounds{common, common}
+		reference := NonDominated(a.Points, b.Points)
+		c.IGD = [2]float64{IGD(a.Points, reference, common), IGD(b.Points, reference, common)}
+		c.Coverage = [2]float64{Coverage(a.Points, b.Points), Coverage(b.Points, a.Points)}
+	}
+	for i, front := range [2][]Point{a.Points, b.Points} {
+		c.Hypervolume[i] = Hypervolume(front, bounds[i])
+		c.Spacing[i] = Spacing(front, bounds[i])
+		c.Spread[i] = Spread(front, bounds[i])
+	}
+	return c
+}
+
+// Print writes the comparison as a table.
+func (c Comparison) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "\t%s\t%s\n", c.Names[0], c.Names[1])
+	fmt.Fprintf(tw, "points\t%d\t%d\n", c.Sizes[0], c.Sizes[1])
+	fmt.Fprintf(tw, "hypervolume\t%.4f\t%.4f\n", c.Hypervolume[0], c.Hypervolume[1])
+	fmt.Fprintf(tw, "spacing\t%.4f\t%.4f\n", c.Spacing[0], c.Spacing[1])
+	fmt.Fprintf(tw, "spread\t%.4f\t%.4f\n", c.Spread[0], c.Spread[1])
+	if c.SameBounds {
+		fmt.Fprintf(tw, "IGD\t%.4f\t%.4f\n", c.IGD[0], c.IGD[1])
+		fmt.Fprintf(tw, "coverage of the other\t%.3f\t%.3f\n", c.Coverage[0], c.Coverage[1])
+	}
+	tw.Flush()
+	if c.SameBounds {
+		fmt.Fprintln(w, "\nBoth fronts are normalized with their common bounds.")
+	} else {
+		fmt.Fprintln(w, "\nThe fronts come from different instances, each is normalized with its own bounds.")
+	}
+}
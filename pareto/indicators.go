@@ -0,0 +1,166 @@
+package pareto
+
+import (
+	"math"
+)
+
+// ReferencePoint of the hypervolume in normalized objectives, slightly beyond the nadir
+// so that the extreme points of a front contribute to it too.
+const ReferencePoint = 1.1
+
+// Bounds are the ranges of the objectives the indicators are normalized with.
+type Bounds struct {
+	MinLength int `json:"min_length"`
+	MaxLength int `json:"max_length"`
+	MinCost   int `json:"min_cost"`
+	MaxCost   int `json:"max_cost"`
+}
+
+// BoundsOf returns the ideal and nadir point of all given fronts together.
+func BoundsOf(fronts ...[]Point) Bounds {
+	b := Bounds{MinLength: math.MaxInt, MaxLength: math.MinInt, MinCost: math.MaxInt, MaxCost: math.MinInt}
+	for _, front := range fronts {
+		for _, p := range front {
+			b.MinLength, b.MaxLength = min(b.MinLength, p.Length), max(b.MaxLength, p.Length)
+			b.MinCost, b.MaxCost = min(b.MinCost, p.Cost), max(b.MaxCost, p.Cost)
+		}
+	}
+	return b
+}
+
+// normalize maps the objectives of the point to [0, 1], 0 being the ideal and 1 the nadir.
+func (b Bounds) normalize(p Point) (float64, float64) {
+	scale := func(v, lo, hi int) float64 {
+		if hi == lo {
+			return 0
+		}
+		return float64(v-lo) / float64(hi-lo)
+	}
+	return scale(p.Length, b.MinLength, b.MaxLength), scale(p.Cost, b.MinCost, b.MaxCost)
+}
+
+// Hypervolume returns the area dominated by the front up to the reference point,
+// in normalized objectives. The front must be sorted by length, as in an Archive.
+func Hypervolume(front []Point, b Bounds) float64 {
+	volume := 0.0
+	prevCost := ReferencePoint
+	for _, p := range front {
+		x, y := b.normalize(p)
+		if x >= ReferencePoint || y >= prevCost {
+			continue
+		}
+		volume += (ReferencePoint - x) * (prevCost - y)
+		prevCost = y
+	}
+	return volume
+}
+
+// Spacing is the standard deviation of the normalized distance from each point to its
+// nearest neighbour on the front, 0 for evenly spaced points.
+func Spacing(front []Point, b Bounds) float64 {
+	if len(front) < 2 {
+		return 0
+	}
+	distances := make([]float64, len(front))
+	for i, p := range front {
+		distances[i] = math.Inf(1)
+		for j, q := range front {
+			if i != j {
+				distances[i] = math.Min(distances[i], manhattan(b, p, q))
+			}
+		}
+	}
+	mean := meanOf(distances)
+	sum := 0.0
+	for _, d := range distances {
+		sum += (d - mean) * (d - mean)
+	}
+	return math.Sqrt(sum / float64(len(front)-1))
+}
+
+// Spread measures how uniformly the points are distributed along the front as the mean absolute
+// deviation of the gaps between consecutive points relative to the mean gap, 0 for equal gaps.
+// The front must be sorted by length.
+func Spread(front []Point, b Bounds) float64 {
+	if len(front) < 3 {
+		return 0
+	}
+	gaps := make([]float64, len(front)-1)
+	for i := range gaps {
+		gaps[i] = euclidean(b, front[i], front[i+1])
+	}
+	mean := meanOf(gaps)
+	if mean == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, g := range gaps {
+		sum += math.Abs(g - mean)
+	}
+	return sum / (float64(len(gaps)) * mean)
+}
+
+// IGD is the inverted generational distance: the mean normalized distance from each point
+// of the reference front to the nearest point of the front. Lower is better.
+func IGD(front, reference []Point, b Bounds) float64 {
+	if len(front) == 0 || len(reference) == 0 {
+		return math.Inf(1)
+	}
+	sum := 0.0
+	for _, r := range reference {
+		nearest := math.Inf(1)
+		for _, p := range front {
+			nearest = math.Min(nearest, euclidean(b, r, p))
+		}
+		sum += nearest
+	}
+	return sum / float64(len(reference))
+}
+
+// Coverage is the fraction of the points of b that are dominated by or equal to a point of a.
+func Coverage(a, b []Point) float64 {
+	if len(b) == 0 {
+		return 0
+	}
+	covered := 0
+	for _, q := range b {
+		for _, p := range a {
+			if p.Length <= q.Length && p.Cost <= q.Cost {
+				covered++
+				break
+			}
+		}
+	}
+	return float64(covered) / float64(len(b))
+}
+
+// NonDominated returns the points of the given fronts that no other point dominates.
+func NonDominated(fronts ...[]Point) []Point {
+	var archive Archive
+	for _, front := range fronts {
+		for _, p := range front {
+			archive.Add(Point{Length: p.Length, Cost: p.Cost})
+		}
+	}
+	return archive.Points()
+}
+
+func euclidean(b Bounds, p, q Point) float64 {
+	px, py := b.normalize(p)
+	qx, qy := b.normalize(q)
+	return math.Hypot(px-qx, py-qy)
+}
+
+func manhattan(b Bounds, p, q Point) float64 {
+	px, py := b.normalize(p)
+	qx, qy := b.normalize(q)
+	return math.Abs(px-qx) + math.Abs(py-qy)
+}
+
+func meanOf(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
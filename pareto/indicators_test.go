@@ -0,0 +1,48 @@
+package pareto
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHypervolume(t *testing.T) {
+	// Objectives from 0 to 10, normalized by tenths
+	bounds := Bounds{MinLength: 0, MaxLength: 10, MinCost: 0, MaxCost: 10}
+	tests := []struct {
+		name   string
+		front  []Point
+		volume float64
+	}{
+		{"empty", nil, 0},
+		{"ideal point", []Point{{Length: 0, Cost: 0}}, 1.1 * 1.1},
+		{"nadir point", []Point{{Length: 10, Cost: 10}}, 0.1 * 0.1},
+		{"single point", []Point{{Length: 5, Cost: 2}}, 0.6 * 0.9},
+		{"extreme points", []Point{{Length: 0, Cost: 10}, {Length: 10, Cost: 0}}, 1.1*0.1 + 0.1*1},
+		{"staircase", []Point{{Length: 0, Cost: 10}, {Length: 2, Cost: 5}, {Length: 6, Cost: 1}, {Length: 10, Cost: 0}},
+			1.1*0.1 + 0.9*0.5 + 0.5*0.4 + 0.1*0.1},
+		{"dominated point adds nothing", []Point{{Length: 2, Cost: 5}, {Length: 4, Cost: 7}}, 0.9 * 0.6},
+		{"beyond the reference point", []Point{{Length: 5, Cost: 12}, {Length: 12, Cost: 0}}, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if volume := Hypervolume(test.front, bounds); math.Abs(volume-test.volume) > 1e-9 {
+				t.Fatalf("Hypervolume(%v) = %g, expected %g", test.front, volume, test.volume)
+			}
+		})
+	}
+}
+
+func TestHypervolumeOfArchive(t *testing.T) {
+	// Adding a point never shrinks the hypervolume of the archive
+	bounds := Bounds{MinLength: 0, MaxLength: 100, MinCost: 0, MaxCost: 100}
+	var archive Archive
+	previous := 0.0
+	for k := 0; k < 100; k++ {
+		archive.Add(Point{Length: (k * 37) % 101, Cost: (k * 59) % 103})
+		volume := Hypervolume(archive.Points(), bounds)
+		if volume < previous-1e-12 {
+			t.Fatalf("step %d: hypervolume fell from %g to %g", k, previous, volume)
+		}
+		previous = volume
+	}
+}
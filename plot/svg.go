@@ -0,0 +1,164 @@
+package plot
+
+import (
+	"bufio"
+	"evolutionary_computation/utils"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"os"
+)
+
+const (
+	width, height = 1000, 600
+	margin        = 50
+	titleHeight   = 30
+	colorbarWidth = 20
+	colorbarSpace = 110 // room on the right for the colorbar and its labels
+
+	// Node radii in pixels for the cheapest and the most expensive node
+	minRadius, maxRadius = 4.0, 12.6
+	unusedOpacity        = 0.2
+	nodeColor            = "#db7093" // palevioletred
+)
+
+// Tour writes an SVG image of the solution drawn over all nodes of the instance.
+// The radius of a node grows with its cost, nodes outside the solution are faded,
+// and the edges are coloured by their length from yellow (short) to green (long).
+// Instances without node coordinates can't be drawn.
+func Tour(w io.Writer, instance *utils.Instance, solution []int, title string) error {
+	nodes := instance.Nodes
+	if len(nodes) == 0 {
+		return fmt.Errorf("no nodes to plot")
+	}
+	if !instance.HasCoordinates {
+		return fmt.Errorf("%s has no node coordinates to plot", instance.Name)
+	}
+
+	minX, maxX, minY, maxY := nodes[0].X, nodes[0].X, nodes[0].Y, nodes[0].Y
+	minCost, maxCost := nodes[0].Cost, nodes[0].Cost
+	for _, node := range nodes {
+		minX, maxX = min(minX, node.X), max(maxX, node.X)
+		minY, maxY = min(minY, node.Y), max(maxY, node.Y)
+		minCost, maxCost = min(minCost, node.Cost), max(maxCost, node.Cost)
+	}
+
+	// Keep the aspect ratio equal, like the matplotlib plots do
+	areaW := float64(width - 2*margin - colorbarSpace)
+	areaH := float64(height - 2*margin - titleHeight)
+	scale := math.Min(areaW/math.Max(maxX-minX, 1), areaH/math.Max(maxY-minY, 1))
+	offsetX := margin + (areaW-scale*(maxX-minX))/2
+	offsetY := margin + titleHeight + (areaH-scale*(maxY-minY))/2
+
+	px := func(node utils.Node) (float64, float64) {
+		// SVG y axis points down
+		return offsetX + scale*(node.X-minX), offsetY + scale*(maxY-node.Y)
+	}
+	radius := func(node utils.Node) float64 {
+		if maxCost == minCost {
+			return minRadius
+		}
+		// Area of the dot is proportional to the cost, as with the scatter sizes in matplotlib
+		t := float64(node.Cost-minCost) / float64(maxCost-minCost)
+		return math.Sqrt(minRadius*minRadius + t*(maxRadius*maxRadius-minRadius*minRadius))
+	}
+
+	inSolution := make([]bool, len(nodes))
+	for _, id := range solution {
+		inSolution[id] = true
+	}
+
+	lengths := make([]int, len(solution))
+	minLength, maxLength := math.MaxInt, 0
+	for i := range solution {
+		lengths[i] = instance.Distances[solution[i]][solution[(i+1)%len(solution)]]
+		minLength, maxLength = min(minLength, lengths[i]), max(maxLength, lengths[i])
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"sans-serif\">\n", width, height, width, height)
+	fmt.Fprintf(out, "<rect width=\"100%%\" height=\"100%%\" fill=\"white\"/>\n")
+	fmt.Fprintf(out, "<text x=\"%d\" y=\"%d\" text-anchor=\"middle\" font-size=\"16\">%s</text>\n", (width-colorbarSpace)/2, margin, html.EscapeString(title))
+
+	for _, selected := range []bool{false, true} {
+		opacity := 1.0
+		if !selected {
+			opacity = unusedOpacity
+		}
+		for i, node := range nodes {
+			if inSolution[i] != selected {
+				continue
+			}
+			x, y := px(node)
+			fmt.Fprintf(out, "<circle cx=\"%.1f\" cy=\"%.1f\" r=\"%.1f\" fill=\"%s\" fill-opacity=\"%.1f\"><title>%d (cost %d)</title></circle>\n",
+				x, y, radius(node), nodeColor, opacity, i, node.Cost)
+		}
+	}
+
+	for i := range solution {
+		x1, y1 := px(nodes[solution[i]])
+		x2, y2 := px(nodes[solution[(i+1)%len(solution)]])
+		fmt.Fprintf(out, "<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"%s\" stroke-width=\"1.5\"/>\n",
+			x1, y1, x2, y2, edgeColor(normalize(lengths[i], minLength, maxLength)))
+	}
+
+	if len(solution) > 0 {
+		writeColorbar(out, minLength, maxLength)
+	}
+	fmt.Fprintln(out, "</svg>")
+
+	return out.Flush()
+}
+
+// TourFile writes the SVG image of the solution to a file.
+func TourFile(path string, instance *utils.Instance, solution []int, title string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Tour(file, instance, solution, title); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// writeColorbar draws the legend of the edge colours along the right side of the image.
+func writeColorbar(out io.Writer, minLength, maxLength int) {
+	x := width - colorbarSpace + 20
+	top, bottom := margin+titleHeight, height-margin
+
+	fmt.Fprintln(out, "<defs><linearGradient id=\"lengths\" x1=\"0\" y1=\"1\" x2=\"0\" y2=\"0\">")
+	for i := 0; i <= 10; i++ {
+		t := float64(i) / 10
+		fmt.Fprintf(out, "<stop offset=\"%.1f\" stop-color=\"%s\"/>\n", t, edgeColor(t))
+	}
+	fmt.Fprintln(out, "</linearGradient></defs>")
+	fmt.Fprintf(out, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"url(#lengths)\" stroke=\"black\" stroke-width=\"0.5\"/>\n",
+		x, top, colorbarWidth, bottom-top)
+
+	for i := 0; i <= 4; i++ {
+		t := float64(i) / 4
+		y := float64(bottom) - t*float64(bottom-top)
+		value := float64(minLength) + t*float64(maxLength-minLength)
+		fmt.Fprintf(out, "<text x=\"%d\" y=\"%.1f\" font-size=\"11\" dominant-baseline=\"middle\">%.0f</text>\n", x+colorbarWidth+5, y, value)
+	}
+	fmt.Fprintf(out, "<text transform=\"translate(%d %d) rotate(90)\" text-anchor=\"middle\" font-size=\"12\">Cost (Euclidean distance)</text>\n",
+		x+colorbarWidth+50, (top+bottom)/2)
+}
+
+func normalize(value, lo, hi int) float64 {
+	if hi == lo {
+		return 0
+	}
+	return float64(value-lo) / float64(hi-lo)
+}
+
+// edgeColor maps t from [0, 1] to the reversed "summer" colormap of matplotlib,
+// which goes from yellow to green.
+func edgeColor(t float64) string {
+	s := 1 - t
+	r, g, b := s, 0.5+0.5*s, 0.4
+	return fmt.Sprintf("#%02x%02x%02x", int(math.Round(255*r)), int(math.Round(255*g)), int(math.Round(255*b)))
+}
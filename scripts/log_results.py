@@ -4,7 +4,6 @@ import json
 
 import numpy as np
 import pandas as pd
-import datetime as dt
 import matplotlib.pyplot as plt
 import matplotlib.colors as mcolors
 
@@ -81,8 +80,11 @@ def plot_solution(nodes, solution, title, save_path):
     plt.close()
 
 
+# Called by the Go experiment runner as a post-processing hook:
+#   go run . data/TSPA.csv greedy_cycle --hook "python scripts/log_results.py"
+# The runner has already written results.json, the plots are saved next to it.
 if len(sys.argv) != 4:
-    print("Usage: python log_results.py <data.csv> <results.json> <method>")
+    print("Usage: python log_results.py <data.csv> <logs/method/instance/results.json> <method>")
     sys.exit(1)
 
 data_file = sys.argv[1]
@@ -95,20 +97,7 @@ nodes.columns = ["x", "y", "cost"]
 
 results = json.load(open(results_file, "r"))
 
-# folder management
-timestamp = dt.datetime.now().strftime("%m%d")
-file = data_file.replace(".csv", "").replace("data/", "")
-current_folder = f"logs/{method}_{timestamp}/{file}"
-os.makedirs(current_folder, exist_ok=True)
-
-# Save results to results.json
-results["method"] = method
-results["timestamp"] = timestamp
-results["best_solution"] = results.pop("best_solution")
-results["worst_solution"] = results.pop("worst_solution")
-
-with open(f"{current_folder}/results.json", "w") as f:
-    json.dump(results, f, indent=4)
+current_folder = os.path.dirname(results_file)
 
 plot_solution(
     nodes, 
@@ -2,7 +2,6 @@ import os
 import sys
 import json
 import numpy as np
-import datetime as dt
 import matplotlib.pyplot as plt
 
 def plot_similarity_vs_fitness(fitnesses, similarities, title, save_path):
@@ -30,32 +29,21 @@ def plot_similarity_vs_fitness(fitnesses, similarities, title, save_path):
     plt.savefig(save_path)
     plt.close()
 
-if len(sys.argv) != 6:
-    print("Usage: python log_results_glob_conv.py <data_file> <results.json> <method> <similarity_measure> <similarity_to>")
+# Called by the Go experiment runner as a post-processing hook:
+#   go run . data/TSPA.csv global_convexity --hook "python scripts/log_results_glob_conv.py"
+# The runner has already written results.json, the plot is saved next to it.
+if len(sys.argv) != 5:
+    print("Usage: python log_results_glob_conv.py <data_file> <logs/method/instance/measure_to/results.json> <method> <measure>_<to>")
     sys.exit(1)
 
 data_file = sys.argv[1]
 results_file = sys.argv[2]
 method = sys.argv[3]
-similarity_measure = sys.argv[4]
-similarity_to = sys.argv[5]
+similarity_measure, similarity_to = sys.argv[4].rsplit("_", 1)
 
 results = json.load(open(results_file))
 
-# folder management
-timestamp = dt.datetime.now().strftime("%m%d")
-file = data_file.replace(".csv", "").replace("data/", "")
-current_folder = f"logs/{method}_{timestamp}/{file}/{similarity_measure}_{similarity_to}"
-os.makedirs(current_folder, exist_ok=True)
-
-# Save results to results.json
-results["method"] = method
-results["timestamp"] = timestamp
-results["fitnesses"] = results.pop("fitnesses")
-results["similarities"] = results.pop("similarities")
-
-with open(f"{current_folder}/results.json", "w") as f:
-    json.dump(results, f, indent=4)
+current_folder = os.path.dirname(results_file)
 
 plot_similarity_vs_fitness(
     results["fitnesses"], 
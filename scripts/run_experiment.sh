@@ -1,13 +1,5 @@
 #!/bin/bash
 
-# Define instances and methods
-instances=("data/tspA.csv" "data/tspB.csv")
-methods=("random" "nearest_neighbor_end_only" "nearest_neighbor_flexible" "greedy_cycle")
-
-# Loop through instances and methods
-for instance in "${instances[@]}"; do
-    for method in "${methods[@]}"; do
-        echo "Running for instance: $instance with method: $method"
-        go run main.go "$instance" "$method"
-    done
-done
+# Runs every method listed in the manifest on every instance, skipping the ones
+# whose results are already complete. Extra arguments are passed on, e.g. --rerun.
+go run . batch scripts/experiment.json "$@"
@@ -0,0 +1,208 @@
+package solver
+
+import (
+	"context"
+	"encoding/json"
+	"evolutionary_computation/utils"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BudgetKind is the resource a budget limits.
+type BudgetKind int
+
+const (
+	WallTime      BudgetKind = iota // elapsed time
+	CPUTime                         // CPU time of the thread running the method
+	Evaluations                     // full fitness evaluations, see utils.Counters
+	LocalSearches                   // local searches started, see utils.Counters
+)
+
+var budgetKindNames = []string{"wall", "cpu", "evals", "ls"}
+
+func (k BudgetKind) String() string {
+	if k >= 0 && int(k) < len(budgetKindNames) {
+		return budgetKindNames[k]
+	}
+	return fmt.Sprintf("BudgetKind(%d)", int(k))
+}
+
+// Budget limits how long a metaheuristic may search.
+// A zero value means the method uses its own default wall-clock time limit.
+type Budget struct {
+	Kind      BudgetKind
+	TimeLimit time.Duration // of WallTime and CPUTime budgets
+	Limit     int           // of Evaluations and LocalSearches budgets
+	// Calibrate asks for the time limit to be the mean runtime of LS_multi on the instance,
+	// it stays set once the time limit was measured.
+	Calibrate bool
+}
+
+// IsZero tells whether the budget leaves the limit to the method.
+func (b Budget) IsZero() bool {
+	return b.TimeLimit == 0 && b.Limit == 0
+}
+
+// ParseBudget reads a budget given as "<kind>:<limit>", such as "wall:30s", "cpu:20s",
+// "evals:5000" or "ls:300". A bare duration is a wall-clock limit, "calibrate" or
+// "calibrate:cpu" ask for a calibrated wall-clock or CPU time limit and "" for none.
+func ParseBudget(spec string) (Budget, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "default" {
+		return Budget{}, nil
+	}
+	kind, value, found := strings.Cut(spec, ":")
+	if kind == "calibrate" {
+		switch {
+		case !found || value == "wall":
+			return Budget{Kind: WallTime, Calibrate: true}, nil
+		case value == "cpu":
+			return Budget{Kind: CPUTime, Calibrate: true}, nil
+		}
+		return Budget{}, fmt.Errorf("budget %q: only wall or cpu time can be calibrated", spec)
+	}
+	if !found {
+		kind, value = "wall", spec
+	}
+
+	switch kind {
+	case "wall", "cpu":
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return Budget{}, fmt.Errorf("budget %q: %q is not a positive duration such as 30s", spec, value)
+		}
+		if kind == "cpu" {
+			return Budget{Kind: CPUTime, TimeLimit: d}, nil
+		}
+		return Budget{Kind: WallTime, TimeLimit: d}, nil
+	case "evals", "ls":
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return Budget{}, fmt.Errorf("budget %q: %q is not a positive number", spec, value)
+		}
+		if kind == "ls" {
+			return Budget{Kind: LocalSearches, Limit: n}, nil
+		}
+		return Budget{Kind: Evaluations, Limit: n}, nil
+	}
+	return Budget{}, fmt.Errorf("budget %q: unknown kind %q, expected one of %s", spec, kind, strings.Join(budgetKindNames, ", "))
+}
+
+// String formats the budget the way ParseBudget reads it.
+func (b Budget) String() string {
+	switch {
+	case b.IsZero() && b.Calibrate:
+		return "calibrate:" + b.Kind.String()
+	case b.IsZero():
+		return "default"
+	case b.Kind == WallTime || b.Kind == CPUTime:
+		return b.Kind.String() + ":" + b.TimeLimit.String()
+	}
+	return b.Kind.String() + ":" + strconv.Itoa(b.Limit)
+}
+
+// MarshalJSON writes the budget as its string, e.g. "cpu:23.4s", marking calibrated limits.
+func (b Budget) MarshalJSON() ([]byte, error) {
+	if b.Calibrate && !b.IsZero() {
+		return json.Marshal(b.String() + " (calibrated)")
+	}
+	return json.Marshal(b.String())
+}
+
+// UnmarshalJSON reads a budget written by MarshalJSON.
+func (b *Budget) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	spec, calibrated := strings.CutSuffix(s, " (calibrated)")
+	budget, err := ParseBudget(spec)
+	if err != nil {
+		return err
+	}
+	budget.Calibrate = budget.Calibrate || calibrated
+	*b = budget
+	return nil
+}
+
+// Matches tells whether results obtained with the budget b can stand for a run with the
+// requested budget. A calibrated budget matches any calibrated budget of the same kind.
+func (b Budget) Matches(requested Budget) bool {
+	if requested.Calibrate {
+		return b.Calibrate && b.Kind == requested.Kind
+	}
+	return !b.Calibrate && b.String() == requested.String()
+}
+
+// Meter tracks how much of its budget a run has used. The evaluations and local searches are
+// read from the counters of the problem of the run, so the budget and the reported work agree.
+type Meter struct {
+	budget   Budget
+	start    time.Time
+	cpuStart time.Duration
+	cpuOk    bool
+	counters *utils.Counters // nil when nothing is counted
+	ctx      context.Context // nil when the run can't be cancelled
+	observe  func(TracePoint)
+}
+
+// Start begins measuring a run. A zero budget is replaced by a wall-clock limit of def,
+// the default of the method. CPU time is that of the calling thread, which the runner locks
+// the run to; where it can't be measured wall-clock time is used.
+func (b Budget) Start(def time.Duration) *Meter {
+	if b.IsZero() {
+		b = Budget{Kind: WallTime, TimeLimit: def}
+	}
+	m := &Meter{budget: b, start: time.Now()}
+	if b.Kind == CPUTime {
+		m.cpuStart, m.cpuOk = ThreadCPUTime()
+	}
+	return m
+}
+
+// Evaluations returns the number of full fitness evaluations of the run so far.
+func (m *Meter) Evaluations() int {
+	if m.counters == nil {
+		return 0
+	}
+	return m.counters.Fitness
+}
+
+// LocalSearches returns the number of local searches the run started so far.
+func (m *Meter) LocalSearches() int {
+	if m.counters == nil {
+		return 0
+	}
+	return m.counters.LocalSearches
+}
+
+// Observe reports the fitness of the current solution and the best one so far,
+// with the time and evaluations used, to the observer of the run.
+func (m *Meter) Observe(fitness, bestFitness int) {
+	if m.observe != nil {
+		m.observe(TracePoint{Elapsed: time.Since(m.start), Evaluations: m.Evaluations(), Fitness: fitness, BestFitness: bestFitness})
+	}
+}
+
+// Exhausted tells whether the run used up its budget or was cancelled.
+func (m *Meter) Exhausted() bool {
+	if m.ctx != nil && m.ctx.Err() != nil {
+		return true
+	}
+	switch m.budget.Kind {
+	case CPUTime:
+		if m.cpuOk {
+			if now, ok := ThreadCPUTime(); ok {
+				return now-m.cpuStart >= m.budget.TimeLimit
+			}
+		}
+		return time.Since(m.start) >= m.budget.TimeLimit
+	case Evaluations:
+		return m.Evaluations() >= m.budget.Limit
+	case LocalSearches:
+		return m.LocalSearches() >= m.budget.Limit
+	}
+	return time.Since(m.start) >= m.budget.TimeLimit
+}
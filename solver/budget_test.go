@@ -0,0 +1,155 @@
+package solver
+
+import (
+	"context"
+	"encoding/json"
+	"evolutionary_computation/utils"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBudget(t *testing.T) {
+	tests := []struct {
+		spec   string
+		budget Budget
+		text   string // of String, the spec when empty
+	}{
+		{"", Budget{}, "default"},
+		{"default", Budget{}, ""},
+		{"wall:30s", Budget{Kind: WallTime, TimeLimit: 30 * time.Second}, ""},
+		{"1m30s", Budget{Kind: WallTime, TimeLimit: 90 * time.Second}, "wall:1m30s"},
+		{" cpu:250ms ", Budget{Kind: CPUTime, TimeLimit: 250 * time.Millisecond}, "cpu:250ms"},
+		{"evals:5000", Budget{Kind: Evaluations, Limit: 5000}, ""},
+		{"ls:300", Budget{Kind: LocalSearches, Limit: 300}, ""},
+		{"calibrate", Budget{Kind: WallTime, Calibrate: true}, "calibrate:wall"},
+		{"calibrate:wall", Budget{Kind: WallTime, Calibrate: true}, ""},
+		{"calibrate:cpu", Budget{Kind: CPUTime, Calibrate: true}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			budget, err := ParseBudget(test.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if budget != test.budget {
+				t.Fatalf("ParseBudget(%q) = %+v, expected %+v", test.spec, budget, test.budget)
+			}
+			text := test.text
+			if text == "" {
+				text = test.spec
+			}
+			if budget.String() != text {
+				t.Fatalf("String() = %q, expected %q", budget.String(), text)
+			}
+			if again, err := ParseBudget(budget.String()); err != nil || again != budget {
+				t.Fatalf("ParseBudget(%q) = %+v, %v, expected %+v", budget.String(), again, err, budget)
+			}
+		})
+	}
+}
+
+func TestParseBudgetErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		err  string
+	}{
+		{"wall:soon", "not a positive duration"},
+		{"cpu:-5s", "not a positive duration"},
+		{"30", "not a positive duration"},
+		{"evals:0", "not a positive number"},
+		{"ls:many", "not a positive number"},
+		{"steps:10", `unknown kind "steps"`},
+		{"calibrate:evals", "only wall or cpu time can be calibrated"},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			_, err := ParseBudget(test.spec)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("ParseBudget(%q) returned %v, expected an error with %q", test.spec, err, test.err)
+			}
+		})
+	}
+}
+
+func TestBudgetJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget Budget
+		json   string
+	}{
+		{"default", Budget{}, `"default"`},
+		{"evaluations", Budget{Kind: Evaluations, Limit: 800}, `"evals:800"`},
+		{"calibration asked", Budget{Kind: CPUTime, Calibrate: true}, `"calibrate:cpu"`},
+		{"calibrated", Budget{Kind: CPUTime, TimeLimit: 1500 * time.Millisecond, Calibrate: true}, `"cpu:1.5s (calibrated)"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bytes, err := json.Marshal(test.budget)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(bytes) != test.json {
+				t.Fatalf("marshalled to %s, expected %s", bytes, test.json)
+			}
+			var budget Budget
+			if err := json.Unmarshal(bytes, &budget); err != nil {
+				t.Fatal(err)
+			}
+			if budget != test.budget {
+				t.Fatalf("unmarshalled %s to %+v, expected %+v", bytes, budget, test.budget)
+			}
+		})
+	}
+}
+
+func TestBudgetMatches(t *testing.T) {
+	calibratedCPU := Budget{Kind: CPUTime, TimeLimit: 2 * time.Second, Calibrate: true}
+	tests := []struct {
+		name              string
+		stored, requested Budget
+		matches           bool
+	}{
+		{"same limit", Budget{Kind: Evaluations, Limit: 10}, Budget{Kind: Evaluations, Limit: 10}, true},
+		{"other limit", Budget{Kind: Evaluations, Limit: 10}, Budget{Kind: Evaluations, Limit: 20}, false},
+		{"other kind", Budget{Kind: WallTime, TimeLimit: time.Second}, Budget{Kind: CPUTime, TimeLimit: time.Second}, false},
+		{"calibrated for a calibration", calibratedCPU, Budget{Kind: CPUTime, Calibrate: true}, true},
+		{"calibrated of another kind", calibratedCPU, Budget{Kind: WallTime, Calibrate: true}, false},
+		{"calibrated for a fixed limit", calibratedCPU, Budget{Kind: CPUTime, TimeLimit: 2 * time.Second}, false},
+		{"fixed limit for a calibration", Budget{Kind: CPUTime, TimeLimit: 2 * time.Second}, Budget{Kind: CPUTime, Calibrate: true}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if matches := test.stored.Matches(test.requested); matches != test.matches {
+				t.Fatalf("%v.Matches(%v) = %v, expected %v", test.stored, test.requested, matches, test.matches)
+			}
+		})
+	}
+}
+
+func TestMeterCounters(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget Budget
+		step   func(counters *utils.Counters)
+	}{
+		{"evaluations", Budget{Kind: Evaluations, Limit: 5}, func(c *utils.Counters) { c.Evaluated() }},
+		{"local searches", Budget{Kind: LocalSearches, Limit: 3}, func(c *utils.Counters) { c.LocalSearch() }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var counters utils.Counters
+			var problem utils.Problem
+			meter := Run{Problem: problem.WithCounters(&counters), Budget: test.budget}.Start(context.Background(), time.Hour)
+			for k := 0; k < test.budget.Limit; k++ {
+				if meter.Exhausted() {
+					t.Fatalf("exhausted after %d of %d", k, test.budget.Limit)
+				}
+				test.step(&counters)
+			}
+			if !meter.Exhausted() {
+				t.Fatalf("not exhausted after %d with counters %+v", test.budget.Limit, counters)
+			}
+		})
+	}
+}
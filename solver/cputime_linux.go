@@ -0,0 +1,18 @@
+package solver
+
+import (
+	"syscall"
+	"time"
+)
+
+const rusageThread = 1 // RUSAGE_THREAD, not exported by the syscall package
+
+// ThreadCPUTime returns the CPU time used by the calling OS thread.
+// The goroutine has to be locked to its thread for the value to be meaningful.
+func ThreadCPUTime() (time.Duration, bool) {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(rusageThread, &usage); err != nil {
+		return 0, false
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano()), true
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package solver
+
+import "time"
+
+// ThreadCPUTime returns the CPU time used by the calling OS thread.
+// It is only implemented on Linux, elsewhere it reports that the time is unavailable.
+func ThreadCPUTime() (time.Duration, bool) {
+	return 0, false
+}
@@ -0,0 +1,229 @@
+package solver
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParamType is the type of a solver parameter.
+type ParamType int
+
+const (
+	Int ParamType = iota
+	Float
+)
+
+func (t ParamType) String() string {
+	switch t {
+	case Int:
+		return "int"
+	case Float:
+		return "float"
+	}
+	return fmt.Sprintf("ParamType(%d)", int(t))
+}
+
+// Param describes a tunable parameter of a solver.
+// Values outside [Min, Max] are rejected; the range is not checked when Max <= Min.
+type Param struct {
+	Name     string
+	Type     ParamType
+	Default  any
+	Min, Max float64
+	Usage    string
+}
+
+// Parse converts a textual value to the type of the parameter and checks its range.
+func (p Param) Parse(value string) (any, error) {
+	var v any
+	var f float64
+	switch p.Type {
+	case Int:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %q is not an integer", p.Name, value)
+		}
+		v, f = i, float64(i)
+	case Float:
+		x, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %q is not a number", p.Name, value)
+		}
+		v, f = x, x
+	default:
+		return nil, fmt.Errorf("parameter %s: unknown type %v", p.Name, p.Type)
+	}
+	if p.Max > p.Min && (f < p.Min || f > p.Max) {
+		return nil, fmt.Errorf("parameter %s: %s is outside of the range [%g, %g]", p.Name, value, p.Min, p.Max)
+	}
+	return v, nil
+}
+
+// Params holds the parameter values a solver runs with.
+type Params map[string]any
+
+// Defaults returns the default values of the given parameters.
+func Defaults(params []Param) Params {
+	values := make(Params, len(params))
+	for _, p := range params {
+		values[p.Name] = p.Default
+	}
+	return values
+}
+
+// Resolve starts from the defaults and applies the overrides on top of them.
+// Unknown parameter names are an error.
+func Resolve(params []Param, overrides map[string]string) (Params, error) {
+	values := Defaults(params)
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		i := indexOfParam(params, name)
+		if i == -1 {
+			return nil, fmt.Errorf("unknown parameter %q (available: %s)", name, strings.Join(paramNames(params), ", "))
+		}
+		v, err := params[i].Parse(overrides[name])
+		if err != nil {
+			return nil, err
+		}
+		values[name] = v
+	}
+	return values, nil
+}
+
+// Int returns the value of an integer parameter.
+func (p Params) Int(name string) int {
+	switch v := p[name].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	panic(fmt.Sprintf("solver: parameter %q is not set", name))
+}
+
+// Float returns the value of a floating point parameter.
+func (p Params) Float(name string) float64 {
+	switch v := p[name].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	panic(fmt.Sprintf("solver: parameter %q is not set", name))
+}
+
+// Equal tells whether both hold the same values of the same parameters. Values are compared as
+// numbers, so that parameters read back from JSON, where all numbers are float64, equal the ints
+// they were written from.
+func (p Params) Equal(other Params) bool {
+	if len(p) != len(other) {
+		return false
+	}
+	for name, value := range p {
+		a, okA := number(value)
+		b, okB := number(other[name])
+		if !okA || !okB || a != b {
+			return false
+		}
+	}
+	return true
+}
+
+func number(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// String formats the parameters as name=value pairs, the same way they are given on the command line.
+func (p Params) String() string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%s=%v", name, p[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// ParseMethod splits a method given on the command line, such as
+// "custom:temperature=800,tenure=30", into its name and parameter overrides.
+func ParseMethod(spec string) (string, map[string]string, error) {
+	name, list, found := strings.Cut(spec, ":")
+	overrides := make(map[string]string)
+	if !found || list == "" {
+		return name, overrides, nil
+	}
+
+	for _, pair := range strings.Split(list, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return "", nil, fmt.Errorf("invalid parameter %q in %q, expected name=value", pair, spec)
+		}
+		overrides[key] = value
+	}
+	return name, overrides, nil
+}
+
+// LoadConfig reads parameter overrides from a JSON file that maps method names
+// to parameter values, for example {"custom": {"temperature": 800, "tenure": 30}}.
+func LoadConfig(filename string) (map[string]map[string]string, error) {
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(bytes, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	config := make(map[string]map[string]string, len(raw))
+	for method, values := range raw {
+		config[method] = make(map[string]string, len(values))
+		for name, value := range values {
+			var s string
+			if err := json.Unmarshal(value, &s); err != nil {
+				s = string(value) // numbers are kept as written in the file
+			}
+			config[method][name] = s
+		}
+	}
+	return config, nil
+}
+
+func indexOfParam(params []Param, name string) int {
+	for i, p := range params {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func paramNames(params []Param) []string {
+	names := make([]string, len(params))
+	for i, p := range params {
+		names[i] = p.Name
+	}
+	return names
+}
@@ -0,0 +1,65 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+var registry = make(map[string]Solver)
+
+// Register makes a solver available under its name.
+// It is meant to be called from init functions and panics on duplicate names.
+func Register(s Solver) {
+	if _, ok := registry[s.Name()]; ok {
+		panic(fmt.Sprintf("solver: %q registered twice", s.Name()))
+	}
+	registry[s.Name()] = s
+}
+
+// Lookup returns the solver registered under the given name.
+func Lookup(name string) (Solver, bool) {
+	s, ok := registry[name]
+	return s, ok
+}
+
+// Names returns the names of all registered solvers in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SolveFunc performs a single run of a method.
+type SolveFunc func(ctx context.Context, run Run) Result
+
+type funcSolver struct {
+	name   string
+	params []Param
+	solve  SolveFunc
+}
+
+func (s *funcSolver) Name() string    { return s.name }
+func (s *funcSolver) Params() []Param { return s.params }
+func (s *funcSolver) Solve(ctx context.Context, run Run) Result {
+	return s.solve(ctx, run)
+}
+
+// New returns a solver backed by a function.
+func New(name string, params []Param, solve SolveFunc) Solver {
+	return &funcSolver{name: name, params: params, solve: solve}
+}
+
+// Method builds a single solution, like the construction heuristics and plain local searches do.
+// When ctx is cancelled local searches stop improving and return their current solution.
+type Method func(ctx context.Context, run Run) []int
+
+// NewMethod returns a solver for a method that builds one solution per run.
+func NewMethod(name string, method Method, params ...Param) Solver {
+	return New(name, params, func(ctx context.Context, run Run) Result {
+		return NewResult(run.Problem, method(ctx, run))
+	})
+}
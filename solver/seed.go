@@ -0,0 +1,25 @@
+package solver
+
+import (
+	"math/rand"
+	"time"
+)
+
+// NewSeed returns a seed based on the current time, for experiments where none was given.
+func NewSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// DeriveSeed returns the seed of the i-th run of an experiment started with the given seed.
+// Seeds of consecutive runs are decorrelated with the splitmix64 finalizer.
+func DeriveSeed(seed int64, i int) int64 {
+	z := uint64(seed) + uint64(i+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// NewRng returns a random number generator of a run.
+func NewRng(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
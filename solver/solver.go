@@ -0,0 +1,73 @@
+package solver
+
+import (
+	"context"
+	"evolutionary_computation/utils"
+	"math/rand"
+	"time"
+)
+
+// Solver is a method that builds a solution for the selective cycle problem.
+type Solver interface {
+	// Name is the identifier used on the command line and in the logs directory.
+	Name() string
+	// Params lists the parameters the solver accepts.
+	Params() []Param
+	// Solve performs a single run of the method.
+	Solve(ctx context.Context, run Run) Result
+}
+
+// Restricted is implemented by solvers that can't solve every problem, like exact methods.
+type Restricted interface {
+	// Supports returns an error if the solver can't solve the problem.
+	Supports(problem *utils.Problem) error
+}
+
+// Run holds everything a solver needs for a single run.
+// All randomness of the run must come from Rng, so that the run can be replayed from its Seed.
+type Run struct {
+	Problem   *utils.Problem
+	StartNode int
+	Params    Params
+	Budget    Budget
+	Seed      int64
+	Rng       *rand.Rand
+	// Observe receives the progress of the run when it is traced, it may be nil
+	Observe func(TracePoint)
+}
+
+// Start begins measuring the run against its budget, see Budget.Start.
+// The budget is also exhausted once ctx is cancelled, the evaluations and local searches are
+// those the counters of the problem record, and the progress the method reports to the meter
+// is passed on to Observe.
+func (r Run) Start(ctx context.Context, def time.Duration) *Meter {
+	m := r.Budget.Start(def)
+	m.counters, m.ctx, m.observe = r.Problem.Counters(), ctx, r.Observe
+	return m
+}
+
+// Result is the outcome of a single run. The work done in the run is counted by the counters
+// of its problem, see utils.Counters.
+type Result struct {
+	Solution   []int
+	Fitness    int
+	Iterations int // iterations of the main loop of the method
+	Trace      []TracePoint
+}
+
+// TracePoint records the state of a run at some moment.
+type TracePoint struct {
+	Elapsed     time.Duration // since the start of the run
+	Evaluations int           // full fitness evaluations so far
+	Fitness     int           // of the current solution
+	BestFitness int           // of the best solution so far
+}
+
+// NewResult evaluates the solution and wraps it in a Result.
+func NewResult(problem *utils.Problem, solution []int) Result {
+	return Result{
+		Solution:   solution,
+		Fitness:    problem.Fitness(solution),
+		Iterations: 1,
+	}
+}
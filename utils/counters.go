@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MoveKind is a kind of local search move.
+type MoveKind int
+
+const (
+	TwoNodesExchange MoveKind = iota
+	TwoEdgesExchange
+	InterRouteExchange
+	InsertNode
+	RemoveNode
+	SegmentRelocation
+	numMoveKinds
+)
+
+var moveKindNames = [numMoveKinds]string{"two_nodes_exchange", "two_edges_exchange", "inter_route_exchange", "insert_node", "remove_node", "segment_relocation"}
+
+func (k MoveKind) String() string {
+	if k >= 0 && k < numMoveKinds {
+		return moveKindNames[k]
+	}
+	return fmt.Sprintf("MoveKind(%d)", int(k))
+}
+
+// Counters count the work done in a run. Each run has its own counters, see Problem.WithCounters.
+// All methods are safe to call on nil counters, which count nothing.
+type Counters struct {
+	Fitness       int               // full fitness evaluations
+	Deltas        [numMoveKinds]int // delta evaluations by move kind
+	Moves         [numMoveKinds]int // applied moves by kind
+	LocalSearches int               // local searches started, or repairs of methods without one
+	Iterations    int               // of the main loop of the method, as reported by the method
+}
+
+// Evaluated records a full fitness evaluation.
+func (c *Counters) Evaluated() {
+	if c != nil {
+		c.Fitness++
+	}
+}
+
+// Delta records the evaluation of the delta of a move.
+func (c *Counters) Delta(kind MoveKind) {
+	if c != nil {
+		c.Deltas[kind]++
+	}
+}
+
+// Move records an applied move.
+func (c *Counters) Move(kind MoveKind) {
+	if c != nil {
+		c.Moves[kind]++
+	}
+}
+
+// LocalSearch records the start of a local search.
+func (c *Counters) LocalSearch() {
+	if c != nil {
+		c.LocalSearches++
+	}
+}
+
+// TotalDeltas returns the number of delta evaluations of all move kinds.
+func (c Counters) TotalDeltas() int {
+	return sum(c.Deltas[:])
+}
+
+// TotalMoves returns the number of applied moves of all kinds.
+func (c Counters) TotalMoves() int {
+	return sum(c.Moves[:])
+}
+
+type countersJSON struct {
+	Fitness       int            `json:"fitness_evaluations"`
+	Deltas        map[string]int `json:"delta_evaluations,omitempty"`
+	Moves         map[string]int `json:"moves,omitempty"`
+	LocalSearches int            `json:"local_searches"`
+	Iterations    int            `json:"iterations"`
+}
+
+// MarshalJSON writes the counts by move kind as objects keyed by the name of the kind.
+func (c Counters) MarshalJSON() ([]byte, error) {
+	out := countersJSON{Fitness: c.Fitness, LocalSearches: c.LocalSearches, Iterations: c.Iterations}
+	out.Deltas, out.Moves = byKind(c.Deltas), byKind(c.Moves)
+	return json.Marshal(out)
+}
+
+func (c *Counters) UnmarshalJSON(data []byte) error {
+	var in countersJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*c = Counters{Fitness: in.Fitness, LocalSearches: in.LocalSearches, Iterations: in.Iterations}
+	for k, name := range moveKindNames {
+		c.Deltas[k], c.Moves[k] = in.Deltas[name], in.Moves[name]
+	}
+	return nil
+}
+
+func byKind(counts [numMoveKinds]int) map[string]int {
+	m := make(map[string]int)
+	for k, n := range counts {
+		if n > 0 {
+			m[moveKindNames[k]] = n
+		}
+	}
+	return m
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
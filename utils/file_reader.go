@@ -1,42 +1,79 @@
 package utils
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Node struct {
-	ID, X, Y, Cost int
+	ID, Cost int
+	X, Y     float64
 }
 
-// LoadNodes reads the CSV file and returns a slice of Node structs, each with an ID.
+// LoadNodes reads an instance file and returns a slice of Node structs, each with an ID.
+// See LoadInstance for the supported formats.
 func LoadNodes(filename string) ([]Node, error) {
-	file, err := os.Open(filename)
+	instance, err := LoadInstance(filename)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+	return instance.Nodes, nil
+}
 
+// CSVLoader reads the headerless semicolon separated x;y;cost format of the course instances.
+type CSVLoader struct{}
+
+// Detect accepts any file, CSV is the fallback format.
+func (CSVLoader) Detect(filename string, head []byte) bool {
+	return true
+}
+
+func (CSVLoader) Load(filename string, r io.Reader) (*Instance, error) {
 	var nodes []Node
-	reader := csv.NewReader(file)
+	reader := csv.NewReader(r)
 	reader.Comma = ';'
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, err
-	}
+	reader.FieldsPerRecord = -1 // checked below to report the line
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected 3 fields x;y;cost, got %d", filename, line, len(record))
+		}
 
-	for i, record := range records {
-		x, _ := strconv.Atoi(record[0])
-		y, _ := strconv.Atoi(record[1])
-		cost, _ := strconv.Atoi(record[2])
+		x, err := strconv.ParseFloat(strings.TrimSpace(record[0]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid x coordinate %q", filename, line, record[0])
+		}
+		y, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid y coordinate %q", filename, line, record[1])
+		}
+		cost, err := strconv.Atoi(strings.TrimSpace(record[2]))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid cost %q", filename, line, record[2])
+		}
 
-		nodes = append(nodes, Node{ID: i, X: x, Y: y, Cost: cost})
+		nodes = append(nodes, Node{ID: len(nodes), X: x, Y: y, Cost: cost})
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("%s: no nodes", filename)
 	}
 
-	return nodes, nil
+	return &Instance{Nodes: nodes, Distances: EuclideanDistances(nodes), HasCoordinates: true}, nil
 }
 
 func LoadBestSolution(filename string) ([]int, error) {
@@ -62,3 +99,77 @@ func LoadBestSolution(filename string) ([]int, error) {
 
 	return data.BestSolution, nil
 }
+
+// LoadSolution reads a solution from a results.json (its best solution), a JSON list,
+// a plain list of node IDs separated by whitespace or commas, or a TSPLIB tour file.
+// TSPLIB tours number the nodes from 1, they are converted to IDs starting at 0.
+// A cycle written out as closed, with the first node repeated at the end, is accepted too.
+func LoadSolution(filename string) ([]int, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var solution []int
+	trimmed := bytes.TrimSpace(content)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		solution, err = LoadBestSolution(filename)
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		err = json.Unmarshal(trimmed, &solution)
+	case bytes.Contains(content, []byte("TOUR_SECTION")):
+		solution, err = parseTSPLIBTour(content)
+	default:
+		solution, err = parseNodeList(content)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	if len(solution) > 1 && solution[0] == solution[len(solution)-1] {
+		solution = solution[:len(solution)-1]
+	}
+	return solution, nil
+}
+
+func parseNodeList(content []byte) ([]int, error) {
+	fields := strings.FieldsFunc(string(content), func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	solution := make([]int, 0, len(fields))
+	for _, field := range fields {
+		node, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node ID %q", field)
+		}
+		solution = append(solution, node)
+	}
+	return solution, nil
+}
+
+func parseTSPLIBTour(content []byte) ([]int, error) {
+	var solution []int
+	inTour := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if !inTour {
+			inTour = text == "TOUR_SECTION"
+			continue
+		}
+		if text == "EOF" {
+			break
+		}
+		for _, field := range strings.Fields(text) {
+			node, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid node ID %q", line, field)
+			}
+			if node == -1 {
+				return solution, nil
+			}
+			solution = append(solution, node-1)
+		}
+	}
+	return solution, scanner.Err()
+}
@@ -1,8 +1,14 @@
 package utils
 
-// Fitness calculates the total cost (sum of distances and node costs) of a given solution.
-func Fitness(solution []int, costMatrix [][]int) int {
-	totalCost := 0
+// Fitness calculates the objective of a given solution, the weighted sum of the length of the cycle and the node costs.
+func (p *Problem) Fitness(solution []int) int {
+	p.counters.Evaluated()
+	return p.Objective(p.Length(solution), p.NodeCost(solution))
+}
+
+// Length calculates the length of the cycle through the nodes of the solution.
+func (p *Problem) Length(solution []int) int {
+	length := 0
 
 	numNodes := len(solution)
 
@@ -10,10 +16,17 @@ func Fitness(solution []int, costMatrix [][]int) int {
 		currentNode := solution[i]
 		nextNode := solution[(i+1)%numNodes] // Ensure wrap-around for the cycle
 
-		totalCost += costMatrix[currentNode][nextNode]
-
+		length += p.Dist[currentNode][nextNode]
 	}
 
-	// The fitness is the sum of total distance and total cost
-	return totalCost
+	return length
+}
+
+// NodeCost calculates the total cost of the nodes of the solution.
+func (p *Problem) NodeCost(solution []int) int {
+	cost := 0
+	for _, node := range solution {
+		cost += p.Costs[node]
+	}
+	return cost
 }
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Instance is a problem instance: the nodes with their costs and the distances between them.
+type Instance struct {
+	Name      string
+	Nodes     []Node
+	Distances [][]int
+	// HasCoordinates is false for instances given only by their edge weights,
+	// the coordinates of their nodes are then meaningless.
+	HasCoordinates bool
+}
+
+// Loader reads instances in one file format.
+type Loader interface {
+	// Detect tells whether a file looks like it is in the format of the loader,
+	// given its name and the first bytes of its content.
+	Detect(filename string, head []byte) bool
+	Load(filename string, r io.Reader) (*Instance, error)
+}
+
+// loaders are tried in order, the first one that detects the format reads the file.
+var loaders = []Loader{TSPLIBLoader{}, CSVLoader{}}
+
+// LoadInstance reads an instance, detecting the format of the file.
+func LoadInstance(filename string) (*Instance, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	head, err := reader.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+
+	for _, loader := range loaders {
+		if loader.Detect(filename, head) {
+			instance, err := loader.Load(filename, reader)
+			if err != nil {
+				return nil, err
+			}
+			if instance.Name == "" {
+				base := filepath.Base(filename)
+				instance.Name = strings.TrimSuffix(base, filepath.Ext(base))
+			}
+			return instance, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: unknown instance format", filename)
+}
+
+// EuclideanDistances returns the matrix of rounded Euclidean distances between the nodes.
+func EuclideanDistances(nodes []Node) [][]int {
+	return distanceMatrix(nodes, CalculateDistance)
+}
+
+func distanceMatrix(nodes []Node, distance func(a, b Node) int) [][]int {
+	matrix := make([][]int, len(nodes))
+	for i := range nodes {
+		matrix[i] = make([]int, len(nodes))
+		for j := range nodes {
+			if i != j {
+				matrix[i][j] = distance(nodes[i], nodes[j])
+			}
+		}
+	}
+	return matrix
+}
+
+// Subset returns the instance restricted to the given nodes, numbered in the given order.
+func (in *Instance) Subset(nodes []int) *Instance {
+	sub := &Instance{
+		Name:           in.Name,
+		Nodes:          make([]Node, len(nodes)),
+		Distances:      make([][]int, len(nodes)),
+		HasCoordinates: in.HasCoordinates,
+	}
+	for i, a := range nodes {
+		sub.Nodes[i] = in.Nodes[a]
+		sub.Nodes[i].ID = i
+		sub.Distances[i] = make([]int, len(nodes))
+		for j, b := range nodes {
+			sub.Distances[i][j] = in.Distances[a][b]
+		}
+	}
+	return sub
+}
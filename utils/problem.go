@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Selection is the range of the number of nodes a solution selects.
+// With Min == Max the size of the solution is fixed, otherwise the method chooses it,
+// trading node costs against the length of the cycle.
+type Selection struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// HalfSelection selects half of the nodes, rounded up, the size of the original problem.
+func HalfSelection(numNodes int) Selection {
+	return Selection{Min: (numNodes + 1) / 2, Max: (numNodes + 1) / 2}
+}
+
+// Fixed tells whether all solutions have the same number of nodes.
+func (s Selection) Fixed() bool {
+	return s.Min == s.Max
+}
+
+func (s Selection) String() string {
+	if s.Fixed() {
+		return strconv.Itoa(s.Min)
+	}
+	return fmt.Sprintf("free:%d-%d", s.Min, s.Max)
+}
+
+// ParseSelection reads the number of nodes to select from the command line. It accepts
+// "half", a number of nodes such as "50", a fraction of the nodes such as "0.3", or
+// "free" to let the method choose any size, optionally limited as in "free:10-0.8".
+// A cycle needs at least 3 nodes.
+func ParseSelection(spec string, numNodes int) (Selection, error) {
+	var s Selection
+	switch {
+	case spec == "" || spec == "half":
+		s = HalfSelection(numNodes)
+	case spec == "free":
+		s = Selection{Min: 3, Max: numNodes}
+	case strings.HasPrefix(spec, "free:"):
+		low, high, ok := strings.Cut(strings.TrimPrefix(spec, "free:"), "-")
+		if !ok {
+			return s, fmt.Errorf("invalid selection %q, expected free:<min>-<max>", spec)
+		}
+		var err error
+		if s.Min, err = parseSelectionSize(low, numNodes); err != nil {
+			return s, err
+		}
+		if s.Max, err = parseSelectionSize(high, numNodes); err != nil {
+			return s, err
+		}
+	default:
+		size, err := parseSelectionSize(spec, numNodes)
+		if err != nil {
+			return s, err
+		}
+		s = Selection{Min: size, Max: size}
+	}
+
+	if s.Min < 3 || s.Max > numNodes || s.Min > s.Max {
+		return s, fmt.Errorf("selection %q gives %v nodes, expected between 3 and the %d nodes of the instance", spec, s, numNodes)
+	}
+	return s, nil
+}
+
+// parseSelectionSize reads a number of nodes, or a fraction of all nodes when it contains a dot.
+func parseSelectionSize(value string, numNodes int) (int, error) {
+	if strings.Contains(value, ".") {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f <= 0 || f > 1 {
+			return 0, fmt.Errorf("invalid fraction of nodes %q", value)
+		}
+		return int(math.Ceil(f * float64(numNodes))), nil
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number of nodes %q", value)
+	}
+	return size, nil
+}
+
+// Weights of the objective alpha*length + beta*cost that solutions minimize.
+type Weights struct {
+	Alpha int `json:"alpha"`
+	Beta  int `json:"beta"`
+}
+
+// UnitWeights adds up the length of the cycle and the costs of its nodes, the original objective.
+var UnitWeights = Weights{Alpha: 1, Beta: 1}
+
+// Objective combines the length of a cycle and the costs of its nodes.
+func (w Weights) Objective(length, cost int) int {
+	return w.Alpha*length + w.Beta*cost
+}
+
+// Point is the position of a node in the plane.
+type Point struct {
+	X, Y float64
+}
+
+// Problem is what the methods solve: the distances between the nodes of the instance,
+// the costs of the nodes, the weights of the objective and how many nodes to select.
+type Problem struct {
+	Dist  [][]int // symmetric
+	Costs []int
+	// Points are the positions of the nodes, nil for instances given only by their edge weights
+	Points []Point
+	Weights
+	Selection
+
+	// Dist and Costs multiplied by their weights
+	distance [][]int
+	cost     []int
+
+	counters *Counters
+}
+
+// NewProblem returns the problem of selecting nodes of the instance.
+// The distances of the instance must be symmetric.
+func NewProblem(instance *Instance, selection Selection, weights Weights) (*Problem, error) {
+	if weights.Alpha < 0 || weights.Beta < 0 || weights.Alpha+weights.Beta == 0 {
+		return nil, fmt.Errorf("invalid weights alpha=%d, beta=%d, expected non-negative and not both 0", weights.Alpha, weights.Beta)
+	}
+
+	n := len(instance.Nodes)
+	costs := make([]int, n)
+	for i, node := range instance.Nodes {
+		costs[i] = node.Cost
+		for j := 0; j < n; j++ {
+			if instance.Distances[i][j] != instance.Distances[j][i] {
+				return nil, fmt.Errorf("distances are not symmetric, %d from %d to %d but %d back",
+					instance.Distances[i][j], i, j, instance.Distances[j][i])
+			}
+		}
+	}
+
+	p := &Problem{Dist: instance.Distances, Costs: costs, Selection: selection}
+	if instance.HasCoordinates {
+		p.Points = make([]Point, n)
+		for i, node := range instance.Nodes {
+			p.Points[i] = Point{X: node.X, Y: node.Y}
+		}
+	}
+	return p.WithWeights(weights), nil
+}
+
+// WithWeights returns the same problem with other weights of the objective,
+// for example with Beta 0 to measure only the length of the cycle.
+func (p *Problem) WithWeights(weights Weights) *Problem {
+	n := len(p.Dist)
+	q := &Problem{
+		Dist:      p.Dist,
+		Costs:     p.Costs,
+		Points:    p.Points,
+		Weights:   weights,
+		Selection: p.Selection,
+		distance:  make([][]int, n),
+		cost:      make([]int, n),
+		counters:  p.counters,
+	}
+	for i := 0; i < n; i++ {
+		q.cost[i] = weights.Beta * p.Costs[i]
+		q.distance[i] = make([]int, n)
+		for j := 0; j < n; j++ {
+			q.distance[i][j] = weights.Alpha * p.Dist[i][j]
+		}
+	}
+	return q
+}
+
+// WithCounters returns the same problem counting the work done on it into c.
+// Runs share a problem, so each run gets its own copy with its own counters.
+func (p *Problem) WithCounters(c *Counters) *Problem {
+	q := *p
+	q.counters = c
+	return &q
+}
+
+// Counters returns the counters of the problem, nil if it doesn't count.
+func (p *Problem) Counters() *Counters {
+	return p.counters
+}
+
+// NumNodes returns the number of nodes of the instance.
+func (p *Problem) NumNodes() int {
+	return len(p.Dist)
+}
+
+// Distance returns the weighted distance between two nodes.
+func (p *Problem) Distance(a, b int) int {
+	return p.distance[a][b]
+}
+
+// Cost returns the weighted cost of a node.
+func (p *Problem) Cost(node int) int {
+	return p.cost[node]
+}
+
+// InsertionCost returns how much the objective grows when the node is inserted between prev and next.
+func (p *Problem) InsertionCost(prev, node, next int) int {
+	return p.distance[prev][node] + p.distance[node][next] - p.distance[prev][next] + p.cost[node]
+}
+
+// Extend keeps inserting the node with the cheapest insertion into the solution while that
+// improves the objective, up to Max nodes. Constructive methods call it once they reach Min
+// nodes, for a fixed selection it leaves the solution as it is.
+func (p *Problem) Extend(solution []int) []int {
+	if len(solution) == 0 {
+		return solution
+	}
+	tour := NewTour(p.NumNodes(), solution)
+
+	for tour.Len() < p.Max {
+		bestNode, bestPosition, bestIncrease := -1, -1, 0
+		for node := 0; node < tour.NumNodes(); node++ {
+			if tour.IsSelected(node) {
+				continue
+			}
+			for j := 0; j < tour.Len(); j++ {
+				next := (j + 1) % tour.Len()
+				increase := p.InsertionCost(tour.At(j), node, tour.At(next))
+				if increase < bestIncrease {
+					bestNode, bestPosition, bestIncrease = node, next, increase
+				}
+			}
+		}
+		if bestNode == -1 {
+			break
+		}
+		tour.Insert(bestPosition, bestNode)
+	}
+	return tour.Solution()
+}
@@ -0,0 +1,203 @@
+package utils
+
+// Tour is a cycle through some of the nodes of a problem. Besides the order of the nodes it keeps
+// the position of every node and the set of selected nodes, so that looking up the position,
+// the neighbours or the selection of a node takes O(1).
+type Tour struct {
+	order      []int    // the selected nodes in the order of the cycle
+	pos        []int    // position of each node in order, -1 for unselected nodes
+	selected   []uint64 // bitset of the selected nodes
+	unselected []int    // the other nodes
+	upos       []int    // position of each unselected node in unselected, -1 for selected nodes
+}
+
+// NewTour returns the tour visiting the nodes in the given order, out of numNodes nodes.
+// The order is copied.
+func NewTour(numNodes int, order []int) *Tour {
+	t := &Tour{
+		order:    append(make([]int, 0, numNodes), order...),
+		pos:      make([]int, numNodes),
+		selected: make([]uint64, (numNodes+63)/64),
+		upos:     make([]int, numNodes),
+	}
+	for node := range t.pos {
+		t.pos[node] = -1
+	}
+	for i, node := range t.order {
+		t.pos[node] = i
+		t.selected[node/64] |= 1 << (node % 64)
+	}
+	t.unselected = make([]int, 0, numNodes-len(order))
+	for node := range t.pos {
+		t.upos[node] = -1
+		if t.pos[node] == -1 {
+			t.upos[node] = len(t.unselected)
+			t.unselected = append(t.unselected, node)
+		}
+	}
+	return t
+}
+
+// Clone returns an independent copy of the tour.
+func (t *Tour) Clone() *Tour {
+	return &Tour{
+		order:      append(make([]int, 0, cap(t.order)), t.order...),
+		pos:        append([]int(nil), t.pos...),
+		selected:   append([]uint64(nil), t.selected...),
+		unselected: append(make([]int, 0, cap(t.unselected)), t.unselected...),
+		upos:       append([]int(nil), t.upos...),
+	}
+}
+
+// Len returns the number of selected nodes.
+func (t *Tour) Len() int {
+	return len(t.order)
+}
+
+// NumNodes returns the number of nodes, selected or not.
+func (t *Tour) NumNodes() int {
+	return len(t.pos)
+}
+
+// Order returns the selected nodes in the order of the cycle. The slice belongs to the tour
+// and changes with it, see Solution for a copy.
+func (t *Tour) Order() []int {
+	return t.order
+}
+
+// Solution returns a copy of the order of the nodes.
+func (t *Tour) Solution() []int {
+	return append([]int(nil), t.order...)
+}
+
+// Unselected returns the nodes not in the cycle, in no particular order. The slice belongs to the tour.
+func (t *Tour) Unselected() []int {
+	return t.unselected
+}
+
+// At returns the node at position i, which wraps around the cycle in both directions.
+func (t *Tour) At(i int) int {
+	if n := len(t.order); i < 0 || i >= n {
+		i = (i%n + n) % n
+	}
+	return t.order[i]
+}
+
+// Pos returns the position of the node in the cycle, -1 if it is not selected.
+func (t *Tour) Pos(node int) int {
+	return t.pos[node]
+}
+
+// IsSelected tells whether the node is in the cycle.
+func (t *Tour) IsSelected(node int) bool {
+	return t.selected[node/64]&(1<<(node%64)) != 0
+}
+
+// Next returns the node after the selected node.
+func (t *Tour) Next(node int) int {
+	return t.At(t.pos[node] + 1)
+}
+
+// Prev returns the node before the selected node.
+func (t *Tour) Prev(node int) int {
+	return t.At(t.pos[node] - 1)
+}
+
+// Swap exchanges the nodes at positions i and j.
+func (t *Tour) Swap(i, j int) {
+	a, b := t.order[i], t.order[j]
+	t.order[i], t.order[j] = b, a
+	t.pos[a], t.pos[b] = j, i
+}
+
+// Reverse reverses the segment of positions i to j, i <= j, which turns the edges
+// order[i-1]→order[i] and order[j]→order[j+1] into order[i-1]-order[j] and order[i]-order[j+1].
+// When the rest of the cycle is shorter it is reversed instead, which gives the same cycle
+// traversed the other way, so the positions of all nodes may change.
+func (t *Tour) Reverse(i, j int) {
+	n := len(t.order)
+	length := j - i + 1
+	if 2*length > n {
+		// Reverse positions j+1 to i-1+n, wrapping around
+		i, j, length = j+1, i-1+n, n-length
+	}
+	for k := 0; k < length/2; k++ {
+		a, b := (i+k)%n, (j-k)%n
+		t.order[a], t.order[b] = t.order[b], t.order[a]
+		t.pos[t.order[a]], t.pos[t.order[b]] = a, b
+	}
+}
+
+// MoveSegment moves the segment of length nodes from position i on, wrapping around, to between
+// the nodes at positions j and j+1, which are outside of it, reversed or not. It takes O(n) and
+// the positions of all nodes may change.
+func (t *Tour) MoveSegment(i, length, j int, reversed bool) {
+	n := len(t.order)
+	segment := make([]int, length)
+	for k := range segment {
+		segment[k] = t.At(i + k)
+	}
+	if reversed {
+		for a, b := 0, length-1; a < b; a, b = a+1, b-1 {
+			segment[a], segment[b] = segment[b], segment[a]
+		}
+	}
+	// The rest of the cycle from the node after the segment to the node at j, then the segment,
+	// then the rest of the cycle up to the node before the segment
+	order := make([]int, 0, n)
+	for k := i + length; ; k++ {
+		order = append(order, t.At(k))
+		if (k-j)%n == 0 {
+			break
+		}
+	}
+	order = append(order, segment...)
+	for k := j + 1; len(order) < n; k++ {
+		order = append(order, t.At(k))
+	}
+	copy(t.order, order)
+	for k, node := range t.order {
+		t.pos[node] = k
+	}
+}
+
+// Replace puts the unselected node at position i, the node that was there becomes unselected.
+func (t *Tour) Replace(i, node int) {
+	old := t.order[i]
+	u := t.upos[node]
+	t.order[i], t.pos[node], t.pos[old] = node, i, -1
+	t.unselected[u], t.upos[old], t.upos[node] = old, u, -1
+	t.selected[node/64] |= 1 << (node % 64)
+	t.selected[old/64] &^= 1 << (old % 64)
+}
+
+// Insert puts the unselected node at position i, before the node that was there,
+// or at the end when i is Len. It takes O(n).
+func (t *Tour) Insert(i, node int) {
+	t.order = append(t.order, 0)
+	copy(t.order[i+1:], t.order[i:])
+	t.order[i] = node
+	for k := i; k < len(t.order); k++ {
+		t.pos[t.order[k]] = k
+	}
+	t.selected[node/64] |= 1 << (node % 64)
+
+	// The last unselected node takes its place
+	u, last := t.upos[node], t.unselected[len(t.unselected)-1]
+	t.unselected[u], t.upos[last] = last, u
+	t.unselected = t.unselected[:len(t.unselected)-1]
+	t.upos[node] = -1
+}
+
+// Remove unselects the node at position i. It takes O(n).
+func (t *Tour) Remove(i int) {
+	node := t.order[i]
+	t.order = append(t.order[:i], t.order[i+1:]...)
+	for k := i; k < len(t.order); k++ {
+		t.pos[t.order[k]] = k
+	}
+	t.pos[node] = -1
+	t.selected[node/64] &^= 1 << (node % 64)
+	t.upos[node] = len(t.unselected)
+	t.unselected = append(t.unselected, node)
+}
@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// tourOperation applies one random operation to the tour and to a plain slice holding the same cycle.
+type tourOperation struct {
+	name string
+	// possible tells whether the operation applies to a tour of n out of numNodes nodes
+	possible func(n, numNodes int) bool
+	apply    func(rng *rand.Rand, tour *Tour, cycle []int) []int
+}
+
+var tourOperations = []tourOperation{
+	{
+		name:     "Swap",
+		possible: func(n, numNodes int) bool { return n >= 2 },
+		apply: func(rng *rand.Rand, tour *Tour, cycle []int) []int {
+			i, j := rng.Intn(len(cycle)), rng.Intn(len(cycle))
+			tour.Swap(i, j)
+			cycle[i], cycle[j] = cycle[j], cycle[i]
+			return cycle
+		},
+	},
+	{
+		name:     "Reverse",
+		possible: func(n, numNodes int) bool { return n >= 2 },
+		apply: func(rng *rand.Rand, tour *Tour, cycle []int) []int {
+			i := rng.Intn(len(cycle))
+			j := i + rng.Intn(len(cycle)-i)
+			tour.Reverse(i, j)
+			slices.Reverse(cycle[i : j+1])
+			return cycle
+		},
+	},
+	{
+		name:     "MoveSegment",
+		possible: func(n, numNodes int) bool { return n >= 3 },
+		apply: func(rng *rand.Rand, tour *Tour, cycle []int) []int {
+			n := len(cycle)
+			i, length := rng.Intn(n), 1+rng.Intn(n-2)
+			j := (i + length + rng.Intn(n-length)) % n // outside of the segment
+			reversed := rng.Intn(2) == 0
+			after := cycle[j]
+
+			segment := make([]int, length)
+			for k := range segment {
+				segment[k] = cycle[(i+k)%n]
+			}
+			rest := make([]int, 0, n)
+			for k := i + length; k < i+n; k++ {
+				rest = append(rest, cycle[k%n])
+			}
+			if reversed {
+				slices.Reverse(segment)
+			}
+			tour.MoveSegment(i, length, j, reversed)
+			at := slices.Index(rest, after) + 1
+			return slices.Concat(rest[:at], segment, rest[at:])
+		},
+	},
+	{
+		name:     "Replace",
+		possible: func(n, numNodes int) bool { return n >= 1 && n < numNodes },
+		apply: func(rng *rand.Rand, tour *Tour, cycle []int) []int {
+			i, node := rng.Intn(len(cycle)), tour.Unselected()[rng.Intn(len(tour.Unselected()))]
+			tour.Replace(i, node)
+			cycle[i] = node
+			return cycle
+		},
+	},
+	{
+		name:     "Insert",
+		possible: func(n, numNodes int) bool { return n < numNodes },
+		apply: func(rng *rand.Rand, tour *Tour, cycle []int) []int {
+			i, node := rng.Intn(len(cycle)+1), tour.Unselected()[rng.Intn(len(tour.Unselected()))]
+			tour.Insert(i, node)
+			return slices.Insert(cycle, i, node)
+		},
+	},
+	{
+		name:     "Remove",
+		possible: func(n, numNodes int) bool { return n >= 1 },
+		apply: func(rng *rand.Rand, tour *Tour, cycle []int) []int {
+			i := rng.Intn(len(cycle))
+			tour.Remove(i)
+			return slices.Delete(cycle, i, i+1)
+		},
+	},
+}
+
+// sameCycle tells whether the sequences are the same cycle, starting anywhere and in either direction.
+func sameCycle(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	if len(a) == 0 {
+		return true
+	}
+	start := slices.Index(b, a[0])
+	if start == -1 {
+		return false
+	}
+	n := len(a)
+	forward, backward := true, true
+	for k := range a {
+		forward = forward && a[k] == b[(start+k)%n]
+		backward = backward && a[k] == b[(start-k+n)%n]
+	}
+	return forward || backward
+}
+
+// checkTour fails the test if the positions, the selection or the unselected nodes of the tour
+// disagree with its order.
+func checkTour(t *testing.T, tour *Tour) {
+	t.Helper()
+	inOrder := make([]bool, tour.NumNodes())
+	for i, node := range tour.Order() {
+		if inOrder[node] {
+			t.Fatalf("node %d is twice in %v", node, tour.Order())
+		}
+		inOrder[node] = true
+		if tour.Pos(node) != i {
+			t.Fatalf("node %d is at %d, Pos returns %d", node, i, tour.Pos(node))
+		}
+		if next := tour.At(i + 1); tour.Next(node) != next {
+			t.Fatalf("Next(%d) = %d, expected %d", node, tour.Next(node), next)
+		}
+		if prev := tour.At(i - 1); tour.Prev(node) != prev {
+			t.Fatalf("Prev(%d) = %d, expected %d", node, tour.Prev(node), prev)
+		}
+	}
+	if len(tour.Order())+len(tour.Unselected()) != tour.NumNodes() {
+		t.Fatalf("%d selected and %d unselected nodes out of %d", len(tour.Order()), len(tour.Unselected()), tour.NumNodes())
+	}
+	for u, node := range tour.Unselected() {
+		if inOrder[node] {
+			t.Fatalf("node %d is both selected and unselected", node)
+		}
+		if tour.upos[node] != u || tour.Pos(node) != -1 {
+			t.Fatalf("unselected node %d at %d has upos %d and Pos %d", node, u, tour.upos[node], tour.Pos(node))
+		}
+	}
+	for node, selected := range inOrder {
+		if tour.IsSelected(node) != selected {
+			t.Fatalf("IsSelected(%d) = %v, expected %v", node, tour.IsSelected(node), selected)
+		}
+	}
+}
+
+func TestTourOperations(t *testing.T) {
+	for _, numNodes := range []int{1, 2, 5, 64, 130} {
+		rng := rand.New(rand.NewSource(int64(numNodes)))
+		initial := rng.Perm(numNodes)[:(numNodes+1)/2]
+		tour, cycle := NewTour(numNodes, initial), slices.Clone(initial)
+		checkTour(t, tour)
+
+		for step := 0; step < 3000; step++ {
+			op := tourOperations[rng.Intn(len(tourOperations))]
+			if !op.possible(len(cycle), numNodes) {
+				continue
+			}
+			cycle = op.apply(rng, tour, cycle)
+			checkTour(t, tour)
+			if !sameCycle(tour.Order(), cycle) {
+				t.Fatalf("%d nodes, step %d: after %s the tour is %v, expected the cycle %v", numNodes, step, op.name, tour.Order(), cycle)
+			}
+			// Reverse and MoveSegment may move all nodes, the next operation works on positions
+			cycle = append(cycle[:0], tour.Order()...)
+		}
+	}
+}
+
+func TestTourClone(t *testing.T) {
+	tour := NewTour(10, []int{3, 1, 4, 5, 9})
+	clone := tour.Clone()
+	clone.Insert(2, 0)
+	clone.Remove(0)
+	clone.Reverse(0, 2)
+	checkTour(t, tour)
+	checkTour(t, clone)
+	if !slices.Equal(tour.Order(), []int{3, 1, 4, 5, 9}) {
+		t.Fatalf("changing the clone changed the tour to %v", tour.Order())
+	}
+}
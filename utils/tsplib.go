@@ -0,0 +1,373 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// TSPLIBLoader reads instances in the TSPLIB .tsp format with EUC_2D, CEIL_2D, ATT, GEO or
+// EXPLICIT edge weights. TSPLIB has no node costs, they are read from an optional
+// NODE_COST_SECTION with "<node> <cost>" lines, or from a sidecar file with the same name
+// and the .costs extension holding either one cost per line or "<node> <cost>" lines.
+// Without either, all node costs are 0.
+type TSPLIBLoader struct{}
+
+var tsplibKeywords = []string{"NAME", "TYPE", "COMMENT", "DIMENSION", "EDGE_WEIGHT_TYPE"}
+
+func (TSPLIBLoader) Detect(filename string, head []byte) bool {
+	if strings.EqualFold(filepath.Ext(filename), ".tsp") {
+		return true
+	}
+	firstLine, _, _ := bytes.Cut(bytes.TrimSpace(head), []byte("\n"))
+	for _, keyword := range tsplibKeywords {
+		if bytes.HasPrefix(firstLine, []byte(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (TSPLIBLoader) Load(filename string, r io.Reader) (*Instance, error) {
+	p := &tsplibParser{filename: filename, scanner: bufio.NewScanner(r), header: make(map[string]string)}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+
+	n := p.dimension
+	nodes := make([]Node, n)
+	for i := range nodes {
+		nodes[i] = Node{ID: i}
+	}
+	if p.coords != nil {
+		for i := range nodes {
+			nodes[i].X, nodes[i].Y = p.coords[i][0], p.coords[i][1]
+		}
+	}
+
+	costs := p.costs
+	if costs == nil {
+		var err error
+		if costs, err = loadSidecarCosts(filename, n); err != nil {
+			return nil, err
+		}
+	}
+	for i := range costs {
+		nodes[i].Cost = costs[i]
+	}
+
+	instance := &Instance{Name: p.header["NAME"], Nodes: nodes, HasCoordinates: p.coords != nil}
+
+	weightType := p.header["EDGE_WEIGHT_TYPE"]
+	switch weightType {
+	case "EXPLICIT":
+		if p.weights == nil {
+			return nil, fmt.Errorf("%s: EXPLICIT edge weights without EDGE_WEIGHT_SECTION", filename)
+		}
+		instance.Distances = p.weights
+	case "EUC_2D", "CEIL_2D", "ATT", "GEO":
+		if p.coords == nil {
+			return nil, fmt.Errorf("%s: %s edge weights without NODE_COORD_SECTION", filename, weightType)
+		}
+		instance.Distances = distanceMatrix(nodes, tsplibDistances[weightType])
+	default:
+		return nil, fmt.Errorf("%s: unsupported EDGE_WEIGHT_TYPE %q", filename, weightType)
+	}
+
+	return instance, nil
+}
+
+var tsplibDistances = map[string]func(a, b Node) int{
+	"EUC_2D": CalculateDistance,
+	"CEIL_2D": func(a, b Node) int {
+		return int(math.Ceil(math.Hypot(a.X-b.X, a.Y-b.Y)))
+	},
+	"ATT": func(a, b Node) int {
+		dx, dy := a.X-b.X, a.Y-b.Y
+		r := math.Sqrt((dx*dx + dy*dy) / 10.0)
+		t := int(math.Round(r))
+		if float64(t) < r {
+			t++
+		}
+		return t
+	},
+	"GEO": func(a, b Node) int {
+		const radius = 6378.388
+		latA, lonA := geoRadians(a.X), geoRadians(a.Y)
+		latB, lonB := geoRadians(b.X), geoRadians(b.Y)
+		q1 := math.Cos(lonA - lonB)
+		q2 := math.Cos(latA - latB)
+		q3 := math.Cos(latA + latB)
+		return int(radius*math.Acos(0.5*((1.0+q1)*q2-(1.0-q1)*q3)) + 1.0)
+	},
+}
+
+// geoRadians converts a TSPLIB GEO coordinate in DDD.MM format to radians.
+func geoRadians(x float64) float64 {
+	const pi = 3.141592 // the value the TSPLIB reference implementation uses
+	deg := math.Trunc(x)
+	minutes := x - deg
+	return pi * (deg + 5.0*minutes/3.0) / 180.0
+}
+
+type tsplibParser struct {
+	filename string
+	scanner  *bufio.Scanner
+	line     int
+
+	header    map[string]string
+	dimension int
+	coords    [][2]float64
+	weights   [][]int
+	costs     []int
+}
+
+func (p *tsplibParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s:%d: %s", p.filename, p.line, fmt.Sprintf(format, args...))
+}
+
+// nextLine returns the next non-empty line, or false at the end of the file.
+func (p *tsplibParser) nextLine() (string, bool) {
+	for p.scanner.Scan() {
+		p.line++
+		if text := strings.TrimSpace(p.scanner.Text()); text != "" {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+func (p *tsplibParser) parse() error {
+	for {
+		text, ok := p.nextLine()
+		if !ok || text == "EOF" {
+			break
+		}
+
+		keyword, value, hasValue := strings.Cut(text, ":")
+		keyword = strings.TrimSpace(keyword)
+		var err error
+		switch {
+		case hasValue:
+			p.header[keyword] = strings.TrimSpace(value)
+			if keyword == "DIMENSION" {
+				if p.dimension, err = strconv.Atoi(p.header[keyword]); err != nil || p.dimension <= 0 {
+					return p.errorf("invalid DIMENSION %q", p.header[keyword])
+				}
+			}
+		case keyword == "NODE_COORD_SECTION" || keyword == "DISPLAY_DATA_SECTION":
+			if keyword == "DISPLAY_DATA_SECTION" && p.coords != nil {
+				_, err = p.readNodeSection(2) // NODE_COORD_SECTION takes precedence
+			} else {
+				p.coords, err = p.readCoords()
+			}
+		case keyword == "EDGE_WEIGHT_SECTION":
+			p.weights, err = p.readWeights()
+		case keyword == "NODE_COST_SECTION":
+			p.costs, err = p.readCosts()
+		case strings.HasSuffix(keyword, "_SECTION"):
+			err = p.skipSection()
+		default:
+			return p.errorf("unexpected line %q", text)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := p.scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %w", p.filename, err)
+	}
+	if p.dimension == 0 {
+		return fmt.Errorf("%s: missing DIMENSION", p.filename)
+	}
+	return nil
+}
+
+// readNodeSection reads DIMENSION lines of the form "<node> <value>..." with values
+// parsed as numbers, indexed by the node numbered from 1.
+func (p *tsplibParser) readNodeSection(numValues int) ([][]float64, error) {
+	if p.dimension == 0 {
+		return nil, p.errorf("section before DIMENSION")
+	}
+	values := make([][]float64, p.dimension)
+	for k := 0; k < p.dimension; k++ {
+		text, ok := p.nextLine()
+		if !ok {
+			return nil, p.errorf("section ends after %d of %d nodes", k, p.dimension)
+		}
+		fields := strings.Fields(text)
+		if len(fields) != numValues+1 {
+			return nil, p.errorf("expected %d fields, got %d in %q", numValues+1, len(fields), text)
+		}
+		id, err := strconv.Atoi(fields[0])
+		if err != nil || id < 1 || id > p.dimension {
+			return nil, p.errorf("invalid node %q", fields[0])
+		}
+		if values[id-1] != nil {
+			return nil, p.errorf("node %d given twice", id)
+		}
+		values[id-1] = make([]float64, numValues)
+		for i, field := range fields[1:] {
+			if values[id-1][i], err = strconv.ParseFloat(field, 64); err != nil {
+				return nil, p.errorf("invalid number %q", field)
+			}
+		}
+	}
+	return values, nil
+}
+
+func (p *tsplibParser) readCoords() ([][2]float64, error) {
+	values, err := p.readNodeSection(2)
+	if err != nil {
+		return nil, err
+	}
+	coords := make([][2]float64, len(values))
+	for i, v := range values {
+		coords[i] = [2]float64{v[0], v[1]}
+	}
+	return coords, nil
+}
+
+func (p *tsplibParser) readCosts() ([]int, error) {
+	values, err := p.readNodeSection(1)
+	if err != nil {
+		return nil, err
+	}
+	costs := make([]int, len(values))
+	for i, v := range values {
+		costs[i] = int(math.Round(v[0]))
+	}
+	return costs, nil
+}
+
+// readWeights reads the EDGE_WEIGHT_SECTION in the layout given by EDGE_WEIGHT_FORMAT.
+// The numbers may be spread over the lines in any way.
+func (p *tsplibParser) readWeights() ([][]int, error) {
+	n := p.dimension
+	if n == 0 {
+		return nil, p.errorf("EDGE_WEIGHT_SECTION before DIMENSION")
+	}
+
+	// Column-wise formats of a symmetric matrix list the same entries as the opposite row-wise ones
+	var cells [][2]int
+	switch format := p.header["EDGE_WEIGHT_FORMAT"]; format {
+	case "FULL_MATRIX":
+		cells = triangle(n, func(i, j int) bool { return true })
+	case "UPPER_ROW", "LOWER_COL":
+		cells = triangle(n, func(i, j int) bool { return j > i })
+	case "LOWER_ROW", "UPPER_COL":
+		cells = triangle(n, func(i, j int) bool { return j < i })
+	case "UPPER_DIAG_ROW", "LOWER_DIAG_COL":
+		cells = triangle(n, func(i, j int) bool { return j >= i })
+	case "LOWER_DIAG_ROW", "UPPER_DIAG_COL":
+		cells = triangle(n, func(i, j int) bool { return j <= i })
+	default:
+		return nil, p.errorf("unsupported EDGE_WEIGHT_FORMAT %q", format)
+	}
+
+	weights := make([][]int, n)
+	for i := range weights {
+		weights[i] = make([]int, n)
+	}
+
+	k := 0
+	for k < len(cells) {
+		text, ok := p.nextLine()
+		if !ok {
+			return nil, p.errorf("EDGE_WEIGHT_SECTION ends after %d of %d weights", k, len(cells))
+		}
+		for _, field := range strings.Fields(text) {
+			if k == len(cells) {
+				return nil, p.errorf("too many weights in EDGE_WEIGHT_SECTION")
+			}
+			w, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, p.errorf("invalid weight %q", field)
+			}
+			i, j := cells[k][0], cells[k][1]
+			weights[i][j] = int(math.Round(w))
+			if p.header["EDGE_WEIGHT_FORMAT"] != "FULL_MATRIX" {
+				weights[j][i] = weights[i][j]
+			}
+			k++
+		}
+	}
+	return weights, nil
+}
+
+// triangle lists the cells (i, j) of an n×n matrix selected by keep, row by row.
+func triangle(n int, keep func(i, j int) bool) [][2]int {
+	var cells [][2]int
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if keep(i, j) {
+				cells = append(cells, [2]int{i, j})
+			}
+		}
+	}
+	return cells
+}
+
+// skipSection skips sections that don't matter here, like FIXED_EDGES_SECTION, up to their -1 terminator.
+func (p *tsplibParser) skipSection() error {
+	for {
+		text, ok := p.nextLine()
+		if !ok || text == "-1" {
+			return nil
+		}
+		if text == "EOF" {
+			return p.errorf("unterminated section")
+		}
+	}
+}
+
+// loadSidecarCosts reads the node costs from <instance>.costs next to the instance file,
+// or returns zero costs when there is no such file.
+func loadSidecarCosts(filename string, n int) ([]int, error) {
+	path := strings.TrimSuffix(filename, filepath.Ext(filename)) + ".costs"
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return make([]int, n), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	costs := make([]int, n)
+	k := 0
+	for line, text := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		index := k
+		if len(fields) == 2 {
+			id, err := strconv.Atoi(fields[0])
+			if err != nil || id < 1 || id > n {
+				return nil, fmt.Errorf("%s:%d: invalid node %q", path, line+1, fields[0])
+			}
+			index = id - 1
+		} else if len(fields) != 1 {
+			return nil, fmt.Errorf("%s:%d: expected \"<cost>\" or \"<node> <cost>\"", path, line+1)
+		}
+		if index >= n {
+			return nil, fmt.Errorf("%s:%d: more costs than the %d nodes of the instance", path, line+1, n)
+		}
+		cost, err := strconv.Atoi(fields[len(fields)-1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid cost %q", path, line+1, fields[len(fields)-1])
+		}
+		costs[index] = cost
+		k++
+	}
+	if k != n {
+		return nil, fmt.Errorf("%s: %d costs for %d nodes", path, k, n)
+	}
+	return costs, nil
+}
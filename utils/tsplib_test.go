@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeInstance writes the content to the named file in a temporary directory and returns its path.
+func writeInstance(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		tsplib   bool
+	}{
+		{"tsp extension", "inst.tsp", "1;2;3\n", true},
+		{"tsp extension in capitals", "INST.TSP", "", true},
+		{"NAME keyword", "inst.txt", "NAME : inst\nDIMENSION : 3\n", true},
+		{"DIMENSION keyword after blank lines", "inst", "\n\nDIMENSION: 3\n", true},
+		{"EDGE_WEIGHT_TYPE keyword", "inst.dat", "EDGE_WEIGHT_TYPE : EUC_2D\n", true},
+		{"semicolon separated", "TSPA.csv", "1355;1796;496\n2524;387;414\n", false},
+		{"keyword on a later line", "inst.csv", "1;2;3\nNAME : inst\n", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if detected := (TSPLIBLoader{}).Detect(test.filename, []byte(test.content)); detected != test.tsplib {
+				t.Fatalf("Detect(%q) = %v, expected %v", test.filename, detected, test.tsplib)
+			}
+		})
+	}
+}
+
+func TestLoadTSPLIB(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		costs     string // content of the sidecar .costs file, none when empty
+		distances [][]int
+		nodeCosts []int
+		coords    bool
+	}{
+		{
+			name: "EUC_2D with node costs",
+			content: `NAME : three
+TYPE : TSP
+DIMENSION : 3
+EDGE_WEIGHT_TYPE : EUC_2D
+NODE_COORD_SECTION
+1 0 0
+3 6 8
+2 3 4
+NODE_COST_SECTION
+1 10
+2 20
+3 30
+EOF
+`,
+			distances: [][]int{{0, 5, 10}, {5, 0, 5}, {10, 5, 0}},
+			nodeCosts: []int{10, 20, 30},
+			coords:    true,
+		},
+		{
+			name: "EUC_2D rounds to the nearest integer",
+			content: `DIMENSION : 2
+EDGE_WEIGHT_TYPE : EUC_2D
+NODE_COORD_SECTION
+1 0 0
+2 1 1
+`,
+			distances: [][]int{{0, 1}, {1, 0}},
+			nodeCosts: []int{0, 0},
+			coords:    true,
+		},
+		{
+			name: "EXPLICIT upper row spread over lines, sidecar costs",
+			content: `NAME : four
+DIMENSION : 4
+EDGE_WEIGHT_TYPE : EXPLICIT
+EDGE_WEIGHT_FORMAT : UPPER_ROW
+EDGE_WEIGHT_SECTION
+1 2
+3 4 5
+6
+EOF
+`,
+			costs:     "7\n8\n9\n10\n",
+			distances: [][]int{{0, 1, 2, 3}, {1, 0, 4, 5}, {2, 4, 0, 6}, {3, 5, 6, 0}},
+			nodeCosts: []int{7, 8, 9, 10},
+		},
+		{
+			name: "EXPLICIT lower diagonal row",
+			content: `DIMENSION : 3
+EDGE_WEIGHT_TYPE : EXPLICIT
+EDGE_WEIGHT_FORMAT : LOWER_DIAG_ROW
+EDGE_WEIGHT_SECTION
+0 1 0 2 3 0
+`,
+			costs:     "1 5\n3 6\n2 7\n",
+			distances: [][]int{{0, 1, 2}, {1, 0, 3}, {2, 3, 0}},
+			nodeCosts: []int{5, 7, 6},
+		},
+		{
+			name: "EXPLICIT full matrix with display data",
+			content: `DIMENSION : 2
+EDGE_WEIGHT_TYPE : EXPLICIT
+EDGE_WEIGHT_FORMAT : FULL_MATRIX
+DISPLAY_DATA_TYPE : TWOD_DISPLAY
+EDGE_WEIGHT_SECTION
+0 4
+4 0
+DISPLAY_DATA_SECTION
+1 0 0
+2 1 1
+EOF
+`,
+			distances: [][]int{{0, 4}, {4, 0}},
+			nodeCosts: []int{0, 0},
+			coords:    true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeInstance(t, "inst.tsp", test.content)
+			if test.costs != "" {
+				if err := os.WriteFile(strings.TrimSuffix(path, ".tsp")+".costs", []byte(test.costs), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			instance, err := LoadInstance(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.EqualFunc(instance.Distances, test.distances, slices.Equal) {
+				t.Fatalf("distances %v, expected %v", instance.Distances, test.distances)
+			}
+			costs := make([]int, len(instance.Nodes))
+			for i, node := range instance.Nodes {
+				costs[i] = node.Cost
+			}
+			if !slices.Equal(costs, test.nodeCosts) {
+				t.Fatalf("node costs %v, expected %v", costs, test.nodeCosts)
+			}
+			if instance.HasCoordinates != test.coords {
+				t.Fatalf("HasCoordinates = %v, expected %v", instance.HasCoordinates, test.coords)
+			}
+		})
+	}
+}
+
+func TestLoadTSPLIBErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		costs   string
+		err     string // expected in the error after the name of the file
+	}{
+		{"invalid dimension", "NAME : x\nDIMENSION : -3\n", "", ":2: invalid DIMENSION"},
+		{"missing dimension", "NAME : x\nEDGE_WEIGHT_TYPE : EUC_2D\n", "", ": missing DIMENSION"},
+		{"section before dimension", "NODE_COORD_SECTION\n1 0 0\n", "", ":1: section before DIMENSION"},
+		{"unexpected line", "DIMENSION : 2\n\nsomething\n", "", ":3: unexpected line"},
+		{"too few fields", "DIMENSION : 2\nEDGE_WEIGHT_TYPE : EUC_2D\nNODE_COORD_SECTION\n1 0 0\n2 1\n", "", ":5: expected 3 fields"},
+		{"node out of range", "DIMENSION : 2\nEDGE_WEIGHT_TYPE : EUC_2D\nNODE_COORD_SECTION\n1 0 0\n3 1 1\n", "", `:5: invalid node "3"`},
+		{"node given twice", "DIMENSION : 2\nEDGE_WEIGHT_TYPE : EUC_2D\nNODE_COORD_SECTION\n1 0 0\n1 1 1\n", "", ":5: node 1 given twice"},
+		{"invalid coordinate", "DIMENSION : 2\nEDGE_WEIGHT_TYPE : EUC_2D\nNODE_COORD_SECTION\n1 0 x\n", "", `:4: invalid number "x"`},
+		{"section cut short", "DIMENSION : 3\nEDGE_WEIGHT_TYPE : EUC_2D\nNODE_COORD_SECTION\n1 0 0\n2 1 1\n", "", ":5: section ends after 2 of 3 nodes"},
+		{"unsupported weight format", "DIMENSION : 2\nEDGE_WEIGHT_TYPE : EXPLICIT\nEDGE_WEIGHT_FORMAT : FUNCTION\nEDGE_WEIGHT_SECTION\n", "", `:4: unsupported EDGE_WEIGHT_FORMAT "FUNCTION"`},
+		{"invalid weight", "DIMENSION : 2\nEDGE_WEIGHT_TYPE : EXPLICIT\nEDGE_WEIGHT_FORMAT : UPPER_ROW\nEDGE_WEIGHT_SECTION\nfar\n", "", `:5: invalid weight "far"`},
+		{"too many weights", "DIMENSION : 2\nEDGE_WEIGHT_TYPE : EXPLICIT\nEDGE_WEIGHT_FORMAT : UPPER_ROW\nEDGE_WEIGHT_SECTION\n1 2\n", "", ":5: too many weights"},
+		{"explicit without weights", "DIMENSION : 2\nEDGE_WEIGHT_TYPE : EXPLICIT\n", "", ": EXPLICIT edge weights without EDGE_WEIGHT_SECTION"},
+		{"coordinates missing", "DIMENSION : 2\nEDGE_WEIGHT_TYPE : GEO\n", "", ": GEO edge weights without NODE_COORD_SECTION"},
+		{"unsupported weight type", "DIMENSION : 2\nEDGE_WEIGHT_TYPE : XRAY1\nNODE_COORD_SECTION\n1 0 0\n2 1 1\n", "", `: unsupported EDGE_WEIGHT_TYPE "XRAY1"`},
+		{"invalid sidecar cost", "DIMENSION : 2\nEDGE_WEIGHT_TYPE : EUC_2D\nNODE_COORD_SECTION\n1 0 0\n2 1 1\n", "4\n\nfree\n", `inst.costs:3: invalid cost "free"`},
+		{"too few sidecar costs", "DIMENSION : 2\nEDGE_WEIGHT_TYPE : EUC_2D\nNODE_COORD_SECTION\n1 0 0\n2 1 1\n", "4\n", "inst.costs: 1 costs for 2 nodes"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeInstance(t, "inst.tsp", test.content)
+			if test.costs != "" {
+				if err := os.WriteFile(strings.TrimSuffix(path, ".tsp")+".costs", []byte(test.costs), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, err := LoadInstance(path)
+			if err == nil {
+				t.Fatalf("loaded %q without an error", test.content)
+			}
+			expected := path + test.err
+			if strings.HasPrefix(test.err, "inst.costs") {
+				expected = filepath.Join(filepath.Dir(path), test.err)
+			}
+			if !strings.HasPrefix(err.Error(), expected) {
+				t.Fatalf("error %q, expected it to start with %q", err, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package utils
+
+import "fmt"
+
+// ValidateSolution checks that the solution is a cycle over distinct nodes with IDs in [0, numNodes)
+// and that the number of its nodes is in the range of the selection.
+func ValidateSolution(solution []int, numNodes int, selection Selection) error {
+	if selection.Fixed() && len(solution) != selection.Min {
+		return fmt.Errorf("solution has %d nodes, expected %d", len(solution), selection.Min)
+	}
+	if len(solution) < selection.Min || len(solution) > selection.Max {
+		return fmt.Errorf("solution has %d nodes, expected between %d and %d", len(solution), selection.Min, selection.Max)
+	}
+	if len(solution) < 3 {
+		return fmt.Errorf("solution has %d nodes, a cycle needs at least 3", len(solution))
+	}
+
+	seen := make([]bool, numNodes)
+	for i, node := range solution {
+		if node < 0 || node >= numNodes {
+			return fmt.Errorf("node %d at position %d is out of range [0, %d)", node, i, numNodes)
+		}
+		if seen[node] {
+			return fmt.Errorf("node %d at position %d is visited twice", node, i)
+		}
+		seen[node] = true
+	}
+	return nil
+}